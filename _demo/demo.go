@@ -14,11 +14,6 @@ import (
 func main() {
 	accessKeyID := strings.TrimSpace(os.Getenv("ACCESS_KEY_ID"))
 	accessKeySec := strings.TrimSpace(os.Getenv("ACCESS_KEY_SECRET"))
-	
-	if (accessKeyID == "") || (accessKeySec == "") {
-		fmt.Printf("ERROR: %s\n", "ACCESS_KEY_ID or ACCESS_KEY_SECRET missing")
-		return
-	}
 
 	zone := ""
 	if len(os.Args) > 1 {
@@ -29,6 +24,8 @@ func main() {
 		return
 	}
 
+	// the default credential chain is used if ACCESS_KEY_ID and ACCESS_KEY_SECRET are missing,
+	// which reads ALIBABA_CLOUD_ACCESS_KEY_ID, ALIBABA_CLOUD_ACCESS_KEY_SECRET and the profiles of Alibaba Cloud CLI
 	fmt.Printf("Get ACCESS_KEY_ID: %s,ACCESS_KEY_SECRET: %s,ZONE: %s\n", accessKeyID, accessKeySec, zone)
 	provider := al.Provider{}
 	provider.AccessKeyID = accessKeyID
@@ -44,8 +41,7 @@ func main() {
 		tmp := record.RR()
 		fmt.Printf("%s (.%s): %s, %s\n", tmp.Name, zone, tmp.Data, tmp.Type)
 		if testName == tmp.Name {
-			r, _ := record.(al.DomainRecord)
-			testID = r.ID
+			testID = recordID(record)
 		}
 	}
 	if testID == "" {
@@ -57,8 +53,7 @@ func main() {
 			TTL:   600,
 		}})
 		if len(records) == 1 {
-			tmp, _ := records[0].(al.DomainRecord)
-			testID = tmp.ID
+			testID = recordID(records[0])
 		}
 		if err != nil {
 			fmt.Printf("ERROR: %s\n", err.Error())
@@ -92,3 +87,16 @@ func main() {
 	}
 
 }
+
+// recordID returns the ID of the record returned by the provider, which is in the
+// ProviderData of typed records like libdns.TXT, or in DomainRecord for other types
+func recordID(record libdns.Record) string {
+	switch r := record.(type) {
+	case libdns.TXT:
+		data, _ := r.ProviderData.(al.ProviderData)
+		return data.RecordID
+	case al.DomainRecord:
+		return r.ID
+	}
+	return ""
+}
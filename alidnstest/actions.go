@@ -0,0 +1,512 @@
+package alidnstest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type handler func(params url.Values) (map[string]interface{}, *apiError)
+
+func (s *Server) handlers() map[string]handler {
+	return map[string]handler{
+		"DescribeDomains":          s.describeDomains,
+		"DescribeDomainInfo":       s.describeDomainInfo,
+		"DescribeDomainRecords":    s.describeDomainRecords,
+		"DescribeSubDomainRecords": s.describeSubDomainRecords,
+		"DescribeDomainRecordInfo": s.describeDomainRecordInfo,
+		"AddDomainRecord":          s.addDomainRecord,
+		"UpdateDomainRecord":       s.updateDomainRecord,
+		"DeleteDomainRecord":       s.deleteDomainRecord,
+		"DeleteSubDomainRecords":   s.deleteSubDomainRecords,
+		"UpdateDomainRecordRemark": s.updateDomainRecordRemark,
+		"UpdateDNSSLBWeight":       s.updateDNSSLBWeight,
+		"DescribeSupportLines":     s.describeSupportLines,
+		"SetDNSSLBStatus":          s.setDNSSLBStatus,
+		"SetDomainRecordStatus":    s.setDomainRecordStatus,
+		"DescribeDNSSLBSubDomains": s.describeDNSSLBSubDomains,
+	}
+}
+
+func intParam(params url.Values, key string) int {
+	result, _ := strconv.Atoi(params.Get(key))
+	return result
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func (s *Server) describeDomains(params url.Values) (map[string]interface{}, *apiError) {
+	keyWord := params.Get("KeyWord")
+	exact := params.Get("SearchMode") == "EXACT"
+	var matched []Zone
+	for _, zone := range s.zones {
+		if keyWord != "" && exact && !strings.EqualFold(zone.DomainName, keyWord) && !strings.EqualFold(zone.PunyCode, keyWord) {
+			continue
+		}
+		if keyWord != "" && !exact && !containsFold(zone.DomainName, keyWord) {
+			continue
+		}
+		if groupID := params.Get("GroupId"); groupID != "" && zone.GroupID != groupID {
+			continue
+		}
+		if rgID := params.Get("ResourceGroupId"); rgID != "" && zone.ResourceGroupID != rgID {
+			continue
+		}
+		matched = append(matched, *zone)
+	}
+	start, end, pageNumber, pageSize := paginate(len(matched), intParam(params, "PageNumber"), intParam(params, "PageSize"), 100)
+	return map[string]interface{}{
+		"TotalCount": len(matched),
+		"PageNumber": pageNumber,
+		"PageSize":   pageSize,
+		"Domains":    map[string]interface{}{"Domain": append([]Zone{}, matched[start:end]...)},
+	}, nil
+}
+
+func (s *Server) describeDomainInfo(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("DomainName") == "" {
+		return nil, errMissing("DomainName")
+	}
+	zone := s.zone(params.Get("DomainName"))
+	if zone == nil {
+		return nil, errZoneNotFound
+	}
+	return map[string]interface{}{
+		"DomainId":    zone.DomainID,
+		"DomainName":  zone.DomainName,
+		"VersionCode": zone.VersionCode,
+		"MinTtl":      zone.minTTL(),
+	}, nil
+}
+
+func (s *Server) recordsPage(matched []Record, params url.Values) map[string]interface{} {
+	start, end, pageNumber, pageSize := paginate(len(matched), intParam(params, "PageNumber"), intParam(params, "PageSize"), 500)
+	return map[string]interface{}{
+		"TotalCount":    len(matched),
+		"PageNumber":    pageNumber,
+		"PageSize":      pageSize,
+		"DomainRecords": map[string]interface{}{"Record": append([]Record{}, matched[start:end]...)},
+	}
+}
+
+// matchesFilters applies the filters shared by DescribeDomainRecords and DescribeSubDomainRecords
+func matchesFilters(rec *Record, params url.Values) bool {
+	if recType := params.Get("Type"); recType != "" && rec.Type != recType {
+		return false
+	}
+	if line := params.Get("Line"); line != "" && rec.Line != line {
+		return false
+	}
+	if status := params.Get("Status"); status != "" && !strings.EqualFold(rec.Status, status) {
+		return false
+	}
+	return true
+}
+
+func (s *Server) describeDomainRecords(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("DomainName") == "" {
+		return nil, errMissing("DomainName")
+	}
+	zone := s.zone(params.Get("DomainName"))
+	if zone == nil {
+		return nil, errZoneNotFound
+	}
+	keyWord := params.Get("KeyWord")
+	mode := strings.ToUpper(params.Get("SearchMode"))
+	var matched []Record
+	for _, rec := range s.records {
+		if rec.DomainName != zone.DomainName || !matchesFilters(rec, params) {
+			continue
+		}
+		switch mode {
+		case "ADVANCED", "COMBINATION":
+			if kw := params.Get("RRKeyWord"); kw != "" && !containsFold(rec.RR, kw) {
+				continue
+			}
+			if kw := params.Get("TypeKeyWord"); kw != "" && rec.Type != strings.ToUpper(kw) {
+				continue
+			}
+			if kw := params.Get("ValueKeyWord"); kw != "" && !containsFold(rec.Value, kw) {
+				continue
+			}
+		case "EXACT":
+			if keyWord != "" && !strings.EqualFold(rec.RR, keyWord) && rec.Value != keyWord {
+				continue
+			}
+		default:
+			if keyWord != "" && !containsFold(rec.RR, keyWord) && !containsFold(rec.Value, keyWord) {
+				continue
+			}
+		}
+		matched = append(matched, *rec)
+	}
+	return s.recordsPage(matched, params), nil
+}
+
+// subDomainOf looks up the zone and the RR of the SubDomain parameter
+func (s *Server) subDomainOf(params url.Values) (*Zone, string, *apiError) {
+	subDomain := strings.ToLower(strings.Trim(params.Get("SubDomain"), "."))
+	if subDomain == "" {
+		return nil, "", errMissing("SubDomain")
+	}
+	var zone *Zone
+	if name := params.Get("DomainName"); name != "" {
+		zone = s.zone(name)
+	} else {
+		for _, z := range s.zones {
+			if subDomain == z.DomainName || strings.HasSuffix(subDomain, "."+z.DomainName) {
+				zone = z
+				break
+			}
+		}
+	}
+	if zone == nil {
+		return nil, "", errZoneNotFound
+	}
+	rr := strings.TrimSuffix(strings.TrimSuffix(subDomain, zone.DomainName), ".")
+	if rr == "" {
+		rr = "@"
+	}
+	return zone, rr, nil
+}
+
+func (s *Server) describeSubDomainRecords(params url.Values) (map[string]interface{}, *apiError) {
+	zone, rr, err := s.subDomainOf(params)
+	if err != nil {
+		return nil, err
+	}
+	var matched []Record
+	for _, rec := range s.records {
+		if rec.DomainName == zone.DomainName && strings.EqualFold(rec.RR, rr) && matchesFilters(rec, params) {
+			matched = append(matched, *rec)
+		}
+	}
+	return s.recordsPage(matched, params), nil
+}
+
+func (s *Server) describeDomainRecordInfo(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("RecordId") == "" {
+		return nil, errMissing("RecordId")
+	}
+	rec := s.record(params.Get("RecordId"))
+	if rec == nil {
+		return nil, errRecordNotFound
+	}
+	return map[string]interface{}{
+		"RecordId":   rec.RecordID,
+		"DomainName": rec.DomainName,
+		"RR":         rec.RR,
+		"Type":       rec.Type,
+		"Value":      rec.Value,
+		"TTL":        rec.TTL,
+		"Priority":   rec.Priority,
+		"Line":       rec.Line,
+		"Status":     rec.Status,
+		"Locked":     rec.Locked,
+		"Weight":     rec.Weight,
+		"Remark":     rec.Remark,
+	}, nil
+}
+
+// recordOf reads the record in the parameters of AddDomainRecord or UpdateDomainRecord,
+// and validates it against the zone and other records.
+func (s *Server) recordOf(zone *Zone, params url.Values, recID string) (Record, *apiError) {
+	for _, key := range []string{"RR", "Type", "Value"} {
+		if params.Get(key) == "" {
+			return Record{}, errMissing(key)
+		}
+	}
+	rec := Record{
+		RecordID:   recID,
+		DomainName: zone.DomainName,
+		RR:         strings.ToLower(params.Get("RR")),
+		Type:       strings.ToUpper(params.Get("Type")),
+		Value:      params.Get("Value"),
+		TTL:        600,
+		Line:       params.Get("Line"),
+	}
+	if rec.Line == "" {
+		rec.Line = "default"
+	}
+	if !zone.supportsLine(rec.Line) {
+		return Record{}, errorOf(http.StatusBadRequest, "InvalidLine", "The line "+rec.Line+" is not supported by the edition of the domain.")
+	}
+	if ttl := params.Get("TTL"); ttl != "" {
+		v, err := strconv.ParseUint(ttl, 10, 32)
+		if err != nil || uint32(v) < zone.minTTL() || v > 86400 {
+			return Record{}, errorOf(http.StatusBadRequest, "DomainRecordTTLInvalid", "The TTL is not allowed by the edition of the domain.")
+		}
+		rec.TTL = uint32(v)
+	}
+	if rec.Type == "MX" {
+		rec.Priority = 10
+		if priority := params.Get("Priority"); priority != "" {
+			v, err := strconv.ParseUint(priority, 10, 32)
+			if err != nil || v < 1 || v > 50 {
+				return Record{}, errorOf(http.StatusBadRequest, "InvalidPriority", "The priority of MX records must be between 1 and 50.")
+			}
+			rec.Priority = uint32(v)
+		}
+	}
+	for _, other := range s.records {
+		if other.DomainName != zone.DomainName {
+			continue
+		}
+		if rec.duplicates(*other) {
+			return Record{}, errDuplicate
+		}
+		if rec.conflicts(*other) {
+			return Record{}, errConflict
+		}
+	}
+	return rec, nil
+}
+
+func (s *Server) addDomainRecord(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("DomainName") == "" {
+		return nil, errMissing("DomainName")
+	}
+	zone := s.zone(params.Get("DomainName"))
+	if zone == nil {
+		return nil, errZoneNotFound
+	}
+	rec, e := s.recordOf(zone, params, "")
+	if e != nil {
+		return nil, e
+	}
+	rec.RecordID = s.newID()
+	rec.Status = "ENABLE"
+	rec.CreateTimestamp = time.Now().UnixMilli()
+	rec.UpdateTimestamp = rec.CreateTimestamp
+	s.records = append(s.records, &rec)
+	return map[string]interface{}{"RecordId": rec.RecordID}, nil
+}
+
+func (s *Server) updateDomainRecord(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("RecordId") == "" {
+		return nil, errMissing("RecordId")
+	}
+	prev := s.record(params.Get("RecordId"))
+	if prev == nil {
+		return nil, errRecordNotFound
+	}
+	if prev.Locked {
+		return nil, errLocked
+	}
+	rec, e := s.recordOf(s.zone(prev.DomainName), params, prev.RecordID)
+	if e != nil {
+		return nil, e
+	}
+	if rec.RR == prev.RR && rec.Type == prev.Type && rec.Value == prev.Value &&
+		rec.TTL == prev.TTL && rec.Priority == prev.Priority && rec.Line == prev.Line {
+		return nil, errDuplicate
+	}
+	prev.RR, prev.Type, prev.Value = rec.RR, rec.Type, rec.Value
+	prev.TTL, prev.Priority, prev.Line = rec.TTL, rec.Priority, rec.Line
+	prev.UpdateTimestamp = time.Now().UnixMilli()
+	return map[string]interface{}{"RecordId": prev.RecordID}, nil
+}
+
+func (s *Server) deleteDomainRecord(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("RecordId") == "" {
+		return nil, errMissing("RecordId")
+	}
+	for i, rec := range s.records {
+		if rec.RecordID != params.Get("RecordId") {
+			continue
+		}
+		if rec.Locked {
+			return nil, errLocked
+		}
+		s.records = append(s.records[:i], s.records[i+1:]...)
+		return map[string]interface{}{"RecordId": rec.RecordID}, nil
+	}
+	return nil, errRecordNotFound
+}
+
+func (s *Server) deleteSubDomainRecords(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("DomainName") == "" {
+		return nil, errMissing("DomainName")
+	}
+	if params.Get("RR") == "" {
+		return nil, errMissing("RR")
+	}
+	zone := s.zone(params.Get("DomainName"))
+	if zone == nil {
+		return nil, errZoneNotFound
+	}
+	matches := func(rec *Record) bool {
+		return rec.DomainName == zone.DomainName && strings.EqualFold(rec.RR, params.Get("RR")) &&
+			(params.Get("Type") == "" || rec.Type == params.Get("Type"))
+	}
+	for _, rec := range s.records {
+		if matches(rec) && rec.Locked {
+			return nil, errLocked
+		}
+	}
+	kept := s.records[:0]
+	count := 0
+	for _, rec := range s.records {
+		if matches(rec) {
+			count++
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	s.records = kept
+	return map[string]interface{}{"RR": params.Get("RR"), "TotalCount": count}, nil
+}
+
+func (s *Server) setDomainRecordStatus(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("RecordId") == "" {
+		return nil, errMissing("RecordId")
+	}
+	status := strings.ToUpper(params.Get("Status"))
+	if status != "ENABLE" && status != "DISABLE" {
+		return nil, errorOf(http.StatusBadRequest, "InvalidStatus", "The status must be Enable or Disable.")
+	}
+	rec := s.record(params.Get("RecordId"))
+	if rec == nil {
+		return nil, errRecordNotFound
+	}
+	if rec.Locked {
+		return nil, errLocked
+	}
+	rec.Status = status
+	rec.UpdateTimestamp = time.Now().UnixMilli()
+	return map[string]interface{}{"RecordId": rec.RecordID, "Status": params.Get("Status")}, nil
+}
+
+func (s *Server) updateDomainRecordRemark(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("RecordId") == "" {
+		return nil, errMissing("RecordId")
+	}
+	rec := s.record(params.Get("RecordId"))
+	if rec == nil {
+		return nil, errRecordNotFound
+	}
+	if utf8.RuneCountInString(params.Get("Remark")) > 50 {
+		return nil, errorOf(http.StatusBadRequest, "InvalidRemark.Length", "The length of the remark exceeds 50 characters.")
+	}
+	rec.Remark = params.Get("Remark")
+	return map[string]interface{}{}, nil
+}
+
+func (s *Server) updateDNSSLBWeight(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("RecordId") == "" {
+		return nil, errMissing("RecordId")
+	}
+	rec := s.record(params.Get("RecordId"))
+	if rec == nil {
+		return nil, errRecordNotFound
+	}
+	weight := intParam(params, "Weight")
+	if weight < 1 || weight > 100 {
+		return nil, errorOf(http.StatusBadRequest, "InvalidWeight", "The weight must be between 1 and 100.")
+	}
+	rec.Weight = weight
+	rec.UpdateTimestamp = time.Now().UnixMilli()
+	return map[string]interface{}{"RecordId": rec.RecordID}, nil
+}
+
+func (s *Server) describeSupportLines(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("DomainName") == "" {
+		return nil, errMissing("DomainName")
+	}
+	zone := s.zone(params.Get("DomainName"))
+	if zone == nil {
+		return nil, errZoneNotFound
+	}
+	return map[string]interface{}{
+		"RecordLines": map[string]interface{}{"RecordLine": zone.lines()},
+	}, nil
+}
+
+func slbKey(zone *Zone, rr, recType string) string {
+	return zone.DomainName + "/" + strings.ToLower(rr) + "/" + recType
+}
+
+func (s *Server) setDNSSLBStatus(params url.Values) (map[string]interface{}, *apiError) {
+	zone, rr, err := s.subDomainOf(params)
+	if err != nil {
+		return nil, err
+	}
+	recType := params.Get("Type")
+	if recType == "" {
+		recType = "A"
+	}
+	open := params.Get("Open") != "false"
+	var matched []*Record
+	for _, rec := range s.records {
+		if rec.DomainName == zone.DomainName && strings.EqualFold(rec.RR, rr) && rec.Type == recType {
+			matched = append(matched, rec)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, errRecordNotFound
+	}
+	s.slb[slbKey(zone, rr, recType)] = open
+	for _, rec := range matched {
+		if open && rec.Weight == 0 {
+			rec.Weight = 1
+		}
+	}
+	return map[string]interface{}{"RecordCount": len(matched), "Open": open}, nil
+}
+
+func (s *Server) describeDNSSLBSubDomains(params url.Values) (map[string]interface{}, *apiError) {
+	if params.Get("DomainName") == "" {
+		return nil, errMissing("DomainName")
+	}
+	zone := s.zone(params.Get("DomainName"))
+	if zone == nil {
+		return nil, errZoneNotFound
+	}
+	type subDomain struct {
+		SubDomain   string `json:"SubDomain"`
+		Type        string `json:"Type"`
+		Open        bool   `json:"Open"`
+		RecordCount int    `json:"RecordCount"`
+	}
+	var matched []subDomain
+	indexes := map[string]int{}
+	for _, rec := range s.records {
+		if rec.DomainName != zone.DomainName {
+			continue
+		}
+		if rr := params.Get("Rr"); rr != "" && !strings.EqualFold(rec.RR, rr) {
+			continue
+		}
+		key := slbKey(zone, rec.RR, rec.Type)
+		i, ok := indexes[key]
+		if !ok {
+			name := zone.DomainName
+			if rec.RR != "@" {
+				name = strings.ToLower(rec.RR) + "." + name
+			}
+			i = len(matched)
+			indexes[key] = i
+			matched = append(matched, subDomain{SubDomain: name, Type: rec.Type, Open: s.slb[key]})
+		}
+		matched[i].RecordCount++
+	}
+	// only the subdomains with multiple records or with SLB enabled are listed
+	available := matched[:0]
+	for _, sd := range matched {
+		if sd.RecordCount > 1 || sd.Open {
+			available = append(available, sd)
+		}
+	}
+	start, end, pageNumber, pageSize := paginate(len(available), intParam(params, "PageNumber"), intParam(params, "PageSize"), 100)
+	return map[string]interface{}{
+		"TotalCount":    len(available),
+		"PageNumber":    pageNumber,
+		"PageSize":      pageSize,
+		"SlbSubDomains": map[string]interface{}{"SlbSubDomain": append([]subDomain{}, available[start:end]...)},
+	}, nil
+}
@@ -0,0 +1,345 @@
+// Package alidnstest provides an in-process fake of the Alidns API for testing
+// code which manages DNS records with github.com/libdns/alidns, without any
+// credential or network access to Aliyun's.
+//
+// The Server verifies ACS3-HMAC-SHA256 signatures of requests and keeps zones
+// and records in memory:
+//
+//	srv := alidnstest.NewServer()
+//	defer srv.Close()
+//	srv.AddZone(alidnstest.Zone{DomainName: "example.com"})
+//	provider := alidns.Provider{
+//		CredentialInfo: alidns.CredentialInfo{
+//			AccessKeyID:     srv.AccessKeyID,
+//			AccessKeySecret: srv.AccessKeySecret,
+//		},
+//		Endpoint:   srv.URL,
+//		HTTPClient: srv.Client(),
+//	}
+//
+// It also serves the STS actions AssumeRole and AssumeRoleWithOIDC, the temporary
+// credentials they issue are accepted by the Server until they expire.
+package alidnstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const apiVersion = "2015-01-09"
+
+// Server is a fake Alidns API server, it is safe for concurrent use
+type Server struct {
+	*httptest.Server
+	// AccessKeyID which requests must be signed with
+	AccessKeyID string
+	// AccessKeySecret which requests must be signed with
+	AccessKeySecret string
+	// Optional SecurityToken which requests must carry if it is not empty
+	SecurityToken string
+
+	mutex     sync.Mutex
+	zones     []*Zone
+	records   []*Record
+	nonces    map[string]bool
+	nextID    int64
+	requests  map[string]int
+	failures  []apiError
+	late      map[string][]apiError
+	sessions  map[string]session
+	oidcToken string
+	slb       map[string]bool
+}
+
+// apiError is the error responded by the Server
+type apiError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+func errorOf(status int, code, message string) *apiError {
+	return &apiError{Status: status, Code: code, Message: message}
+}
+
+func errMissing(param string) *apiError {
+	return errorOf(http.StatusBadRequest, "Missing"+param, "The parameter "+param+" is required.")
+}
+
+var (
+	errZoneNotFound   = errorOf(http.StatusBadRequest, "InvalidDomainName.NoExist", "The specified domain name does not exist.")
+	errRecordNotFound = errorOf(http.StatusBadRequest, "DomainRecordNotBelongToUser", "The DNS record does not exist or does not belong to the user.")
+	errDuplicate      = errorOf(http.StatusBadRequest, "DomainRecordDuplicate", "The DNS record already exists.")
+	errConflict       = errorOf(http.StatusBadRequest, "DomainRecordConflict", "The DNS record conflicts with other records.")
+	errLocked         = errorOf(http.StatusBadRequest, "DomainRecordLocked", "The DNS record is locked.")
+)
+
+// NewServer starts a Server with generated credentials, callers should Close it when finished.
+func NewServer() *Server {
+	s := &Server{
+		AccessKeyID:     "alidnstest-access-key-id",
+		AccessKeySecret: "alidnstest-access-key-secret",
+		nonces:          map[string]bool{},
+		nextID:          1000000000,
+		requests:        map[string]int{},
+		sessions:        map[string]session{},
+		oidcToken:       "alidnstest-oidc-token",
+		slb:             map[string]bool{},
+		late:            map[string][]apiError{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddZone hosts the zone on the Server, VersionCode defaults to EditionFree.
+func (s *Server) AddZone(zone Zone) Zone {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	zone.DomainName = strings.ToLower(strings.Trim(zone.DomainName, "."))
+	if zone.VersionCode == "" {
+		zone.VersionCode = EditionFree
+	}
+	zone.DomainID = s.newID()
+	s.zones = append(s.zones, &zone)
+	return zone
+}
+
+// AddRecord adds the record to the hosted zone of its DomainName directly,
+// missing RecordID, TTL, Line and Status are filled with defaults.
+func (s *Server) AddRecord(rec Record) (Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	zone := s.zone(rec.DomainName)
+	if zone == nil {
+		return Record{}, fmt.Errorf("zone %s is not hosted", rec.DomainName)
+	}
+	rec.DomainName = zone.DomainName
+	if rec.RecordID == "" {
+		rec.RecordID = s.newID()
+	}
+	if rec.TTL == 0 {
+		rec.TTL = 600
+	}
+	if rec.Line == "" {
+		rec.Line = "default"
+	}
+	if rec.Status == "" {
+		rec.Status = "ENABLE"
+	}
+	rec.CreateTimestamp = time.Now().UnixMilli()
+	rec.UpdateTimestamp = rec.CreateTimestamp
+	s.records = append(s.records, &rec)
+	return rec, nil
+}
+
+// Records returns the records of the zone, or of all zones if it is empty.
+func (s *Server) Records(zone string) []Record {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []Record
+	for _, rec := range s.records {
+		if zone == "" || rec.DomainName == strings.ToLower(strings.Trim(zone, ".")) {
+			result = append(result, *rec)
+		}
+	}
+	return result
+}
+
+// Requests returns the number of requests of the action the Server received,
+// including the rejected ones.
+func (s *Server) Requests(action string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[action]
+}
+
+// FailNext makes the next n requests with valid signatures fail with the HTTP status and error code,
+// e.g. FailNext(1, http.StatusBadRequest, "Throttling.User").
+func (s *Server) FailNext(n int, status int, code string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, apiError{Status: status, Code: code, Message: "Injected failure by alidnstest."})
+	}
+}
+
+// FailAfter makes the next n requests of the action fail with the HTTP status and error code after
+// they are handled, so their changes are kept, e.g. FailAfter("AddDomainRecord", 1, http.StatusServiceUnavailable,
+// "ServiceUnavailable") simulates a record which was added while the gateway failed to respond.
+func (s *Server) FailAfter(action string, n int, status int, code string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := 0; i < n; i++ {
+		s.late[action] = append(s.late[action], apiError{Status: status, Code: code, Message: "Injected failure by alidnstest."})
+	}
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.FormatInt(s.nextID, 10)
+}
+
+func (s *Server) zone(name string) *Zone {
+	name = strings.ToLower(strings.Trim(name, "."))
+	for _, zone := range s.zones {
+		if zone.DomainName == name {
+			return zone
+		}
+	}
+	return nil
+}
+
+func (s *Server) record(recID string) *Record {
+	for _, rec := range s.records {
+		if rec.RecordID == recID {
+			return rec
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	requestID := s.newID()
+	s.mutex.Unlock()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, requestID, errorOf(http.StatusBadRequest, "InvalidParameter", err.Error()))
+		return
+	}
+	params, err := url.ParseQuery(string(body))
+	if err != nil {
+		s.writeError(w, requestID, errorOf(http.StatusBadRequest, "InvalidParameter", err.Error()))
+		return
+	}
+	for k, v := range r.URL.Query() {
+		params[k] = append(params[k], v...)
+	}
+	action := r.Header.Get("x-acs-action")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests[action]++
+	handler, isSTS := s.stsHandlers()[action]
+	e := s.verify(r, body, isSTS)
+	if anonymousActions[action] {
+		e = s.verifyAnonymous(r)
+	}
+	if e != nil {
+		s.writeError(w, requestID, e)
+		return
+	}
+	if len(s.failures) > 0 {
+		e := s.failures[0]
+		s.failures = s.failures[1:]
+		s.writeError(w, requestID, &e)
+		return
+	}
+	if !isSTS {
+		handler = s.handlers()[action]
+	}
+	if handler == nil {
+		s.writeError(w, requestID, errorOf(http.StatusNotFound, "InvalidAction.NotFound", "Specified api is not found, please check your url and method."))
+		return
+	}
+	result, e := handler(params)
+	if e != nil {
+		s.writeError(w, requestID, e)
+		return
+	}
+	if late := s.late[action]; len(late) > 0 {
+		s.late[action] = late[1:]
+		s.writeError(w, requestID, &late[0])
+		return
+	}
+	result["RequestId"] = requestID
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// verify checks the ACS3-HMAC-SHA256 signature and common headers of the request,
+// which is signed with the credential of the Server or a temporary one issued by STS actions.
+func (s *Server) verify(r *http.Request, body []byte, isSTS bool) *apiError {
+	auth, ok := parseAuthorization(r.Header.Get("Authorization"))
+	if !ok {
+		return errorOf(http.StatusBadRequest, "IncompleteSignature", "The request signature does not conform to Aliyun standards.")
+	}
+	secret, securityToken := s.AccessKeySecret, s.SecurityToken
+	if auth.AccessKeyID != s.AccessKeyID {
+		sess, ok := s.sessions[auth.AccessKeyID]
+		if !ok {
+			return errorOf(http.StatusNotFound, "InvalidAccessKeyId.NotFound", "Specified access key is not found.")
+		}
+		if time.Now().After(sess.Expiration) {
+			return errorOf(http.StatusBadRequest, "InvalidSecurityToken.Expired", "Specified SecurityToken is expired.")
+		}
+		secret, securityToken = sess.AccessKeySecret, sess.SecurityToken
+	}
+	version := apiVersion
+	if isSTS {
+		version = stsAPIVersion
+	}
+	if r.Header.Get("x-acs-version") != version {
+		return errorOf(http.StatusBadRequest, "InvalidVersion", "Specified parameter Version is not valid.")
+	}
+	if r.Header.Get("x-acs-content-sha256") != hashString(body) {
+		return errorOf(http.StatusBadRequest, "ContentSHA256NotMatched", "The content hash does not match the request body.")
+	}
+	signed := map[string]bool{}
+	for _, k := range auth.SignedHeaders {
+		signed[k] = true
+	}
+	for _, k := range []string{"host", "x-acs-action", "x-acs-version", "x-acs-date", "x-acs-signature-nonce", "x-acs-content-sha256"} {
+		if !signed[k] {
+			return errorOf(http.StatusBadRequest, "IncompleteSignature", "The header "+k+" is not signed.")
+		}
+	}
+	if signature(r, body, auth.SignedHeaders, secret) != auth.Signature {
+		return errorOf(http.StatusBadRequest, "SignatureDoesNotMatch", "Specified signature is not matched with our calculation.")
+	}
+	if len(securityToken) > 0 && r.Header.Get("x-acs-security-token") != securityToken {
+		return errorOf(http.StatusBadRequest, "InvalidSecurityToken.Mismatch", "Specified SecurityToken is not valid.")
+	}
+	date, err := time.Parse(time.RFC3339, r.Header.Get("x-acs-date"))
+	if err != nil || time.Since(date) > 15*time.Minute || time.Until(date) > 15*time.Minute {
+		return errorOf(http.StatusBadRequest, "InvalidTimeStamp.Expired", "Specified time stamp or date value is expired.")
+	}
+	nonce := r.Header.Get("x-acs-signature-nonce")
+	if s.nonces[nonce] {
+		return errorOf(http.StatusBadRequest, "SignatureNonceUsed", "Specified signature nonce was used already.")
+	}
+	s.nonces[nonce] = true
+	return nil
+}
+
+// verifyAnonymous checks the common headers of the request of an anonymous action
+func (s *Server) verifyAnonymous(r *http.Request) *apiError {
+	if len(r.Header.Get("Authorization")) > 0 {
+		return errorOf(http.StatusBadRequest, "InvalidParameter", "The anonymous action should not be signed.")
+	}
+	if r.Header.Get("x-acs-version") != stsAPIVersion {
+		return errorOf(http.StatusBadRequest, "InvalidVersion", "Specified parameter Version is not valid.")
+	}
+	return nil
+}
+
+func (s *Server) writeError(w http.ResponseWriter, requestID string, e *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"RequestId": requestID,
+		"HostId":    "alidns.aliyuncs.com",
+		"Code":      e.Code,
+		"Message":   e.Message,
+		"Recommend": "https://api.aliyun.com/troubleshoot?q=" + e.Code,
+	})
+}
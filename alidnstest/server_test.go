@@ -0,0 +1,186 @@
+package alidnstest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/libdns/alidns"
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func newProvider(srv *alidnstest.Server) *alidns.Provider {
+	return &alidns.Provider{
+		CredentialInfo: alidns.CredentialInfo{
+			AccessKeyID:     srv.AccessKeyID,
+			AccessKeySecret: srv.AccessKeySecret,
+		},
+		Endpoint:    srv.URL,
+		HTTPClient:  srv.Client(),
+		RetryPolicy: alidns.RetryPolicy{MaxAttempts: 1},
+	}
+}
+
+func TestServerSignature(t *testing.T) {
+	srv := alidnstest.NewServer()
+	defer srv.Close()
+	srv.AddZone(alidnstest.Zone{DomainName: "example.com"})
+
+	p := newProvider(srv)
+	if _, err := p.ListZones(context.TODO()); err != nil {
+		t.Fatal("excepted signed request to pass, got:", err)
+	}
+
+	p.AccessKeySecret = "wrong-secret"
+	_, err := p.ListZones(context.TODO())
+	if !alidns.IsAuth(err) {
+		t.Error("excepted SignatureDoesNotMatch, got:", err)
+	}
+
+	p.AccessKeyID = "wrong-id"
+	_, err = p.ListZones(context.TODO())
+	if !alidns.IsAuth(err) {
+		t.Error("excepted InvalidAccessKeyId.NotFound, got:", err)
+	}
+}
+
+func TestServerRecords(t *testing.T) {
+	srv := alidnstest.NewServer()
+	defer srv.Close()
+	srv.AddZone(alidnstest.Zone{DomainName: "example.com"})
+	p := newProvider(srv)
+	ctx := context.TODO()
+
+	recs, err := p.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1", TTL: 600 * time.Second},
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.2"},
+	})
+	if err != nil || len(recs) != 2 {
+		t.Fatal("excepted 2 records appended, got:", recs, err)
+	}
+
+	_, err = p.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+	})
+	if !alidns.IsDuplicate(err) {
+		t.Error("excepted DomainRecordDuplicate, got:", err)
+	}
+
+	_, err = p.AppendRecords(ctx, "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "CNAME", Data: "example.net"},
+	})
+	if err == nil {
+		t.Error("excepted DomainRecordConflict of CNAME")
+	}
+
+	_, err = p.AppendRecords(ctx, "example.org.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+	})
+	if !alidns.IsNotFound(err) {
+		t.Error("excepted zone not found, got:", err)
+	}
+
+	recs, err = p.SetRecords(ctx, "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.3"},
+	})
+	if err != nil || len(recs) != 1 {
+		t.Fatal("excepted 1 record set, got:", recs, err)
+	}
+	if live := srv.Records("example.com"); len(live) != 1 || live[0].Value != "192.0.2.3" {
+		t.Error("excepted the only record 192.0.2.3, got:", live)
+	}
+
+	recs, err = p.DeleteRecords(ctx, "example.com.", []libdns.Record{
+		libdns.RR{Name: "www"},
+	})
+	if err != nil || len(recs) != 1 {
+		t.Fatal("excepted 1 record deleted, got:", recs, err)
+	}
+	if live := srv.Records("example.com"); len(live) != 0 {
+		t.Error("excepted no records, got:", live)
+	}
+}
+
+func TestServerPagination(t *testing.T) {
+	srv := alidnstest.NewServer()
+	defer srv.Close()
+	srv.AddZone(alidnstest.Zone{DomainName: "example.com"})
+	for i := 0; i < 1234; i++ {
+		_, err := srv.AddRecord(alidnstest.Record{
+			DomainName: "example.com",
+			RR:         fmt.Sprintf("host%d", i),
+			Type:       "A",
+			Value:      "192.0.2.1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 150; i++ {
+		srv.AddZone(alidnstest.Zone{DomainName: fmt.Sprintf("example%d.net", i), GroupID: "group"})
+	}
+
+	p := newProvider(srv)
+	p.RecordPageSize = 100
+	for _, concurrency := range []int{1, 4} {
+		p.RecordPageConcurrency = concurrency
+		recs, err := p.GetRecords(context.TODO(), "example.com.")
+		if err != nil || len(recs) != 1234 {
+			t.Error("excepted 1234 records with concurrency", concurrency, "got:", len(recs), err)
+		}
+	}
+
+	zones, err := p.ListZonesWithFilter(context.TODO(), alidns.ZoneFilter{GroupID: "group"})
+	if err != nil || len(zones) != 150 {
+		t.Error("excepted 150 zones, got:", len(zones), err)
+	}
+	zones, err = p.ListZonesWithFilter(context.TODO(), alidns.ZoneFilter{KeyWord: "example1"})
+	if err != nil || len(zones) != 61 {
+		t.Error("excepted 61 zones, got:", len(zones), err)
+	}
+}
+
+func TestServerFailNext(t *testing.T) {
+	srv := alidnstest.NewServer()
+	defer srv.Close()
+	srv.AddZone(alidnstest.Zone{DomainName: "example.com"})
+	p := newProvider(srv)
+
+	srv.FailNext(1, http.StatusBadRequest, "Throttling.User")
+	_, err := p.GetRecords(context.TODO(), "example.com.")
+	if !alidns.IsThrottled(err) {
+		t.Error("excepted Throttling.User, got:", err)
+	}
+	if n := srv.Requests("DescribeDomainRecords"); n != 1 {
+		t.Error("excepted 1 request, got:", n)
+	}
+
+	p.RetryPolicy = alidns.RetryPolicy{BaseDelay: time.Millisecond}
+	srv.FailNext(2, http.StatusServiceUnavailable, "ServiceUnavailable")
+	_, err = p.GetRecords(context.TODO(), "example.com.")
+	if err != nil {
+		t.Error("excepted success after retrying, got:", err)
+	}
+}
+
+func TestServerFailAfter(t *testing.T) {
+	srv := alidnstest.NewServer()
+	defer srv.Close()
+	srv.AddZone(alidnstest.Zone{DomainName: "example.com"})
+	p := newProvider(srv)
+
+	srv.FailAfter("AddDomainRecord", 1, http.StatusServiceUnavailable, "ServiceUnavailable")
+	_, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+	})
+	if !errors.Is(err, &alidns.APIError{Code: "ServiceUnavailable"}) {
+		t.Error("excepted ServiceUnavailable, got:", err)
+	}
+	if live := srv.Records("example.com"); len(live) != 1 {
+		t.Error("excepted the record added before the failure, got:", live)
+	}
+}
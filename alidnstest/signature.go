@@ -0,0 +1,104 @@
+package alidnstest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const signatureAlgorithm = "ACS3-HMAC-SHA256"
+
+// authorization is the parsed Authorization header of ACS3-HMAC-SHA256
+type authorization struct {
+	AccessKeyID   string
+	SignedHeaders []string
+	Signature     string
+}
+
+func parseAuthorization(src string) (authorization, bool) {
+	result := authorization{}
+	rest, ok := strings.CutPrefix(src, signatureAlgorithm+" ")
+	if !ok {
+		return result, false
+	}
+	for _, el := range strings.Split(rest, ",") {
+		kv := strings.SplitN(strings.TrimSpace(el), "=", 2)
+		if len(kv) != 2 {
+			return result, false
+		}
+		switch kv[0] {
+		case "Credential":
+			result.AccessKeyID = kv[1]
+		case "SignedHeaders":
+			result.SignedHeaders = strings.Split(kv[1], ";")
+		case "Signature":
+			result.Signature = kv[1]
+		}
+	}
+	ok = len(result.AccessKeyID) > 0 && len(result.SignedHeaders) > 0 && len(result.Signature) > 0
+	return result, ok
+}
+
+func hashString(src []byte) string {
+	hash := sha256.New()
+	hash.Write(src)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func hmacString(src string, secret string) string {
+	hm := hmac.New(sha256.New, []byte(secret))
+	hm.Write([]byte(src))
+	return hex.EncodeToString(hm.Sum(nil))
+}
+
+func percentEncode(src string) string {
+	result := url.QueryEscape(src)
+	result = strings.Replace(result, "+", "%20", -1)
+	result = strings.Replace(result, "*", "%2A", -1)
+	result = strings.Replace(result, "%7E", "~", -1)
+	return result
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			pairs = append(pairs, percentEncode(k)+"="+percentEncode(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// signature computes the signature of the request as the V3 signing document describes,
+// body is the raw payload of the request.
+func signature(r *http.Request, body []byte, signedHeaders []string, secret string) string {
+	headerLines := ""
+	for _, k := range signedHeaders {
+		v := r.Header.Get(k)
+		if strings.EqualFold(k, "host") {
+			v = r.Host
+		}
+		headerLines += strings.ToLower(k) + ":" + strings.TrimSpace(v) + "\n"
+	}
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := r.Method + "\n" +
+		path + "\n" +
+		canonicalQuery(r.URL.Query()) + "\n" +
+		headerLines + "\n" +
+		strings.Join(signedHeaders, ";") + "\n" +
+		hashString(body)
+	stringToSign := signatureAlgorithm + "\n" + hashString([]byte(canonicalRequest))
+	return hmacString(stringToSign, secret)
+}
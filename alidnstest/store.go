@@ -0,0 +1,139 @@
+package alidnstest
+
+import (
+	"strings"
+)
+
+// Zone is a zone hosted by the Server
+type Zone struct {
+	DomainID        string `json:"DomainId"`
+	DomainName      string `json:"DomainName"`
+	PunyCode        string `json:"PunyCode,omitempty"`
+	VersionCode     string `json:"VersionCode"`
+	GroupID         string `json:"GroupId,omitempty"`
+	ResourceGroupID string `json:"ResourceGroupId,omitempty"`
+	MinTTL          uint32 `json:"-"`
+	// Optional codes of custom lines of the zone, besides the lines supported by its edition
+	CustomLines []string `json:"-"`
+}
+
+// Record is a record hosted by the Server
+type Record struct {
+	RecordID        string `json:"RecordId"`
+	DomainName      string `json:"DomainName"`
+	RR              string `json:"RR"`
+	Type            string `json:"Type"`
+	Value           string `json:"Value"`
+	TTL             uint32 `json:"TTL"`
+	Priority        uint32 `json:"Priority,omitempty"`
+	Line            string `json:"Line"`
+	Status          string `json:"Status"`
+	Locked          bool   `json:"Locked"`
+	Weight          int    `json:"Weight,omitempty"`
+	Remark          string `json:"Remark,omitempty"`
+	CreateTimestamp int64  `json:"CreateTimestamp,omitempty"`
+	UpdateTimestamp int64  `json:"UpdateTimestamp,omitempty"`
+}
+
+const (
+	// EditionFree is the VersionCode of zones in the free edition, which is the default of AddZone
+	EditionFree = "mianfei"
+	// EditionEnterpriseBasic is the VersionCode of zones in the basic enterprise edition
+	EditionEnterpriseBasic = "version_enterprise_basic"
+)
+
+// Line is a resolution line which records can be served on
+type Line struct {
+	LineCode        string `json:"LineCode"`
+	LineName        string `json:"LineName"`
+	LineDisplayName string `json:"LineDisplayName"`
+	FatherCode      string `json:"FatherCode,omitempty"`
+}
+
+// basicLines are the lines supported by zones in all editions
+var basicLines = []Line{
+	{LineCode: "default", LineName: "默认", LineDisplayName: "默认"},
+	{LineCode: "telecom", LineName: "电信", LineDisplayName: "电信"},
+	{LineCode: "unicom", LineName: "联通", LineDisplayName: "联通"},
+	{LineCode: "mobile", LineName: "移动", LineDisplayName: "移动"},
+	{LineCode: "oversea", LineName: "境外", LineDisplayName: "境外"},
+	{LineCode: "edu", LineName: "教育网", LineDisplayName: "教育网"},
+	{LineCode: "search", LineName: "搜索引擎", LineDisplayName: "搜索引擎"},
+	{LineCode: "google", LineName: "谷歌", LineDisplayName: "谷歌", FatherCode: "search"},
+}
+
+// enterpriseLines are the lines supported by zones in enterprise editions only
+var enterpriseLines = []Line{
+	{LineCode: "cn_telecom_beijing", LineName: "北京电信", LineDisplayName: "北京电信", FatherCode: "telecom"},
+	{LineCode: "os_asia", LineName: "亚洲", LineDisplayName: "亚洲", FatherCode: "oversea"},
+}
+
+// lines returns the lines supported by the edition of the zone, and the custom lines of the zone
+func (z Zone) lines() []Line {
+	result := append([]Line{}, basicLines...)
+	if strings.Contains(z.VersionCode, "enterprise") {
+		result = append(result, enterpriseLines...)
+	}
+	for _, code := range z.CustomLines {
+		result = append(result, Line{LineCode: code, LineName: code, LineDisplayName: code})
+	}
+	return result
+}
+
+func (z Zone) supportsLine(code string) bool {
+	for _, line := range z.lines() {
+		if line.LineCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (z Zone) minTTL() uint32 {
+	if z.MinTTL > 0 {
+		return z.MinTTL
+	}
+	if strings.Contains(z.VersionCode, "enterprise") {
+		return 1
+	}
+	return 600
+}
+
+// conflicts reports whether r cannot coexist with v in the same zone
+func (r Record) conflicts(v Record) bool {
+	if r.RecordID == v.RecordID || !strings.EqualFold(r.RR, v.RR) || r.Line != v.Line {
+		return false
+	}
+	return (r.Type == "CNAME") != (v.Type == "CNAME")
+}
+
+// duplicates reports whether r is the same record as v
+func (r Record) duplicates(v Record) bool {
+	return r.RecordID != v.RecordID &&
+		strings.EqualFold(r.RR, v.RR) &&
+		r.Type == v.Type &&
+		r.Value == v.Value &&
+		r.Line == v.Line
+}
+
+// paginate returns the page of n items and the normalized page number and size
+func paginate(n int, pageNumber, pageSize, maxPageSize int) (int, int, int, int) {
+	if pageNumber <= 0 {
+		pageNumber = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	start := (pageNumber - 1) * pageSize
+	if start > n {
+		start = n
+	}
+	end := start + pageSize
+	if end > n {
+		end = n
+	}
+	return start, end, pageNumber, pageSize
+}
@@ -0,0 +1,123 @@
+package alidnstest
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const stsAPIVersion = "2015-04-01"
+
+// session is a temporary credential issued by the STS actions of the Server
+type session struct {
+	AccessKeySecret string
+	SecurityToken   string
+	RoleArn         string
+	Expiration      time.Time
+}
+
+func (s *Server) stsHandlers() map[string]handler {
+	return map[string]handler{
+		"AssumeRole":         s.assumeRole,
+		"AssumeRoleWithOIDC": s.assumeRoleWithOIDC,
+	}
+}
+
+// anonymousActions are the STS actions which are not signed
+var anonymousActions = map[string]bool{
+	"AssumeRoleWithOIDC": true,
+}
+
+// OIDCToken returns the OIDC token which AssumeRoleWithOIDC requests must carry
+func (s *Server) OIDCToken() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.oidcToken
+}
+
+// RotateOIDCToken replaces the OIDC token which AssumeRoleWithOIDC requests must carry,
+// and returns the new one.
+func (s *Server) RotateOIDCToken() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.oidcToken = "alidnstest-oidc-token-" + s.newID()
+	return s.oidcToken
+}
+
+// Sessions returns the number of temporary credentials issued for the role by the STS actions,
+// e.g. AssumeRole.
+func (s *Server) Sessions(roleArn string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := 0
+	for _, el := range s.sessions {
+		if el.RoleArn == roleArn {
+			result++
+		}
+	}
+	return result
+}
+
+// issueSession validates the parameters shared by the STS actions and issues a temporary credential
+func (s *Server) issueSession(params url.Values) (map[string]interface{}, *apiError) {
+	roleArn := params.Get("RoleArn")
+	if roleArn == "" {
+		return nil, errMissing("RoleArn")
+	}
+	if !strings.HasPrefix(roleArn, "acs:ram::") || !strings.Contains(roleArn, ":role/") {
+		return nil, errorOf(http.StatusBadRequest, "InvalidParameter.RoleArn", "The parameter RoleArn is wrongly formed.")
+	}
+	sessionName := params.Get("RoleSessionName")
+	if sessionName == "" {
+		return nil, errMissing("RoleSessionName")
+	}
+	duration := 3600
+	if params.Get("DurationSeconds") != "" {
+		duration = intParam(params, "DurationSeconds")
+		if duration < 900 || duration > 43200 {
+			return nil, errorOf(http.StatusBadRequest, "InvalidParameter.DurationSeconds", "The Min/Max value of DurationSeconds is 15min/12hr.")
+		}
+	}
+	accessKeyID := "STS." + s.newID()
+	sess := session{
+		AccessKeySecret: "secret-" + s.newID(),
+		SecurityToken:   "token-" + s.newID(),
+		RoleArn:         roleArn,
+		Expiration:      time.Now().Add(time.Duration(duration) * time.Second).UTC(),
+	}
+	s.sessions[accessKeyID] = sess
+	return map[string]interface{}{
+		"Credentials": map[string]string{
+			"AccessKeyId":     accessKeyID,
+			"AccessKeySecret": sess.AccessKeySecret,
+			"SecurityToken":   sess.SecurityToken,
+			"Expiration":      sess.Expiration.Format(time.RFC3339),
+		},
+		"AssumedRoleUser": map[string]string{
+			"Arn":           roleArn + "/" + sessionName,
+			"AssumedRoleId": s.newID() + ":" + sessionName,
+		},
+	}, nil
+}
+
+func (s *Server) assumeRole(params url.Values) (map[string]interface{}, *apiError) {
+	return s.issueSession(params)
+}
+
+func (s *Server) assumeRoleWithOIDC(params url.Values) (map[string]interface{}, *apiError) {
+	providerArn := params.Get("OIDCProviderArn")
+	if providerArn == "" {
+		return nil, errMissing("OIDCProviderArn")
+	}
+	if !strings.HasPrefix(providerArn, "acs:ram::") || !strings.Contains(providerArn, ":oidc-provider/") {
+		return nil, errorOf(http.StatusBadRequest, "InvalidParameter.OIDCProviderArn", "The parameter OIDCProviderArn is wrongly formed.")
+	}
+	if params.Get("OIDCToken") == "" {
+		return nil, errMissing("OIDCToken")
+	}
+	if params.Get("OIDCToken") != s.oidcToken {
+		return nil, errorOf(http.StatusBadRequest, "AuthenticationFail.OIDCToken.Invalid", "The OIDC token is invalid or expired.")
+	}
+	return s.issueSession(params)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -16,34 +15,55 @@ type aliClient struct {
 	schema          *aliClientSchema
 	DomainName      string
 	InstanceEdition instanceEdition
+	MinTTL          ttl_t
 	mutex           sync.Mutex
+	cred            *CredentialInfo
+	retry           RetryPolicy
+	httpClient      *http.Client
+	action          string
+	requestBody     keyPairs
+	attempts        int
+	version         string
+	// whether a former attempt failed without knowing if the request was processed
+	uncertain bool
 }
 
 func (c *aliClient) IsEntprienseEdition() bool {
 	return c.InstanceEdition.IsEnterpriseEdition()
 }
 
-func getClient(cred *CredentialInfo, zone ...string) (*aliClient, error) {
+// ttlOf returns the TTL which the zone of the client actually accepts
+func (c *aliClient) ttlOf(ttl ttl_t) ttl_t {
+	if ttl > 0 && c.MinTTL > 0 {
+		return min(ttl, c.MinTTL)
+	}
+	return c.InstanceEdition.ttlOf(ttl)
+}
+
+func (c *aliClient) withZone(info aliZoneInfo) *aliClient {
+	c.DomainName = info.DomainName
+	c.InstanceEdition = info.Edition
+	c.MinTTL = info.MinTTL
+	return c
+}
+
+// domainName returns the canonical name of the zone if the client is with the zone,
+// otherwise the zone as is without the trailing dot
+func (c *aliClient) domainName(zone string) string {
+	if len(c.DomainName) > 0 {
+		return c.DomainName
+	}
+	return strings.Trim(zone, ".")
+}
+
+func getClient(cred *CredentialInfo) (*aliClient, error) {
 	result := &aliClient{}
 	schema, err := getClientSchema(cred, "https")
 	if err != nil {
 		return result, err
 	}
 	result.schema = schema
-	if len(zone) == 0 {
-		return result, nil
-	}
-	tmp, _ := getClient(cred)
-	info, err := tmp.queryDomainInfo(context.Background(), strings.Trim(zone[0], "."))
-	if err != nil {
-		return result, err
-	}
-	if len(info.DomainName) > 0 {
-		result.DomainName = info.DomainName
-	}
-	if len(info.VersionCode) > 0 {
-		result.InstanceEdition = info.VersionCode
-	}
+	result.cred = cred
 	return result, nil
 }
 
@@ -51,14 +71,29 @@ func (c *aliClient) SetAction(action string) error {
 	if c.schema == nil {
 		return errors.New("schema was not initialed proprely")
 	}
+	c.action = action
 	return c.schema.SetAction(action)
 }
 
+// SetVersion sets the API version of the request, for the actions of products other than Alidns, e.g. STS
+func (c *aliClient) SetVersion(version string) error {
+	if c.schema == nil {
+		return errors.New("schema was not initialed proprely")
+	}
+	c.version = version
+	return c.schema.UpsertHeader("x-acs-version", version)
+}
+
 func (c *aliClient) SetRequestBody(key string, value string) error {
 	if c.schema == nil {
 		return errors.New("schema was not initialed proprely")
 	}
-	return c.schema.UpsertRequestBody(key, value)
+	err := c.schema.UpsertRequestBody(key, value)
+	if err != nil {
+		return err
+	}
+	c.requestBody, _ = c.requestBody.Upsert(key, value)
+	return nil
 }
 
 func (c *aliClient) Lock() {
@@ -74,12 +109,42 @@ func (c *aliClient) doAPIRequest(ctx context.Context, result interface{}, method
 	if len(methods) > 0 {
 		method = methods[0]
 	}
+	for attempt := 1; ; attempt++ {
+		err := c.sendAPIRequest(ctx, result, method)
+		c.attempts = attempt
+		if err != nil && attempt < c.retry.maxAttempts() && outcomeUnknown(err) {
+			c.uncertain = true
+		}
+		if err == nil {
+			c.schema = nil
+			return nil
+		}
+		if attempt >= c.retry.maxAttempts() || ctx.Err() != nil || !c.retry.retryable(c.action, err) {
+			return err
+		}
+		if !sleepWithContext(ctx, c.retry.backoff(attempt)) {
+			return err
+		}
+		err = c.renewSchema()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sendAPIRequest sends the request in the schema once, it returns *APIError
+// if the API responded with an error.
+func (c *aliClient) sendAPIRequest(ctx context.Context, result interface{}, method string) error {
 	req, err := c.schema.HttpRequest(ctx, method)
 	if err != nil {
 		return err
 	}
 
-	rsp, err := http.DefaultClient.Do(req)
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	rsp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -91,13 +156,40 @@ func (c *aliClient) doAPIRequest(ctx context.Context, result interface{}, method
 		return err
 	}
 
-	err = json.Unmarshal(buf, result)
+	if rsp.StatusCode != 200 {
+		return apiErrorOf(rsp.StatusCode, buf)
+	}
+	return json.Unmarshal(buf, result)
+}
+
+// renewSchema signs the same request again with a fresh nonce and timestamp
+func (c *aliClient) renewSchema() error {
+	if c.cred == nil {
+		return errors.New("credential of the client is missing")
+	}
+	schema, err := getClientSchema(c.cred, "https")
 	if err != nil {
 		return err
 	}
-	if rsp.StatusCode != 200 {
-		return fmt.Errorf("get error status: HTTP %d: %+v", rsp.StatusCode, result.(*aliDomainResult).Msg)
+	schema.APIHost = c.schema.APIHost
+	c.schema = schema
+	if len(c.version) > 0 {
+		err = c.schema.UpsertHeader("x-acs-version", c.version)
+		if err != nil {
+			return err
+		}
+	}
+	if len(c.action) > 0 {
+		err = c.schema.SetAction(c.action)
+		if err != nil {
+			return err
+		}
+	}
+	for _, el := range c.requestBody {
+		err = c.schema.UpsertRequestBody(el.Key, el.Value)
+		if err != nil {
+			return err
+		}
 	}
-	c.schema = nil
-	return err
+	return nil
 }
@@ -22,11 +22,70 @@ func (c *aliClient) queryDomainInfo(ctx context.Context, zone string) (aliDomain
 		return aliDomainInfo{}, err
 	}
 	if len(rs.Domains.Domain) == 0 {
-		return aliDomainInfo{}, errors.New("cannot found specified zone:" + zone)
+		return aliDomainInfo{}, fmt.Errorf("cannot found specified zone %s: %w", zone, ErrNotFound)
 	}
 	return rs.Domains.Domain[0], err
 }
 
+func (c *aliClient) queryDomainDetail(ctx context.Context, zone string) (aliDomainResult, error) {
+	if c.schema == nil {
+		return aliDomainResult{}, errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("DescribeDomainInfo")
+	c.SetRequestBody("DomainName", zone)
+	rs := aliDomainResult{}
+	err := c.doAPIRequest(ctx, &rs)
+	if err != nil {
+		return aliDomainResult{}, err
+	}
+	return rs, err
+}
+
+func (c *aliClient) querySupportLines(ctx context.Context, zone string) ([]Line, error) {
+	if c.schema == nil {
+		return nil, errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("DescribeSupportLines")
+	c.SetRequestBody("DomainName", zone)
+	rs := aliDomainResult{}
+	err := c.doAPIRequest(ctx, &rs)
+	if err != nil {
+		return nil, err
+	}
+	return rs.RecordLines.RecordLine, nil
+}
+
+func (c *aliClient) queryDomainList(ctx context.Context, filter ZoneFilter, pageNumber, pageSize int) (aliDomainResult, error) {
+	if c.schema == nil {
+		return aliDomainResult{}, errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("DescribeDomains")
+	c.SetRequestBody("PageNumber", fmt.Sprintf("%d", pageNumber))
+	c.SetRequestBody("PageSize", fmt.Sprintf("%d", pageSize))
+	if filter.GroupID != "" {
+		c.SetRequestBody("GroupId", filter.GroupID)
+	}
+	if filter.ResourceGroupID != "" {
+		c.SetRequestBody("ResourceGroupId", filter.ResourceGroupID)
+	}
+	if filter.KeyWord != "" {
+		c.SetRequestBody("KeyWord", filter.KeyWord)
+		c.SetRequestBody("SearchMode", "LIKE")
+	}
+	rs := aliDomainResult{}
+	err := c.doAPIRequest(ctx, &rs)
+	if err != nil {
+		return aliDomainResult{}, err
+	}
+	return rs, err
+}
+
 func (c *aliClient) addDomainRecord(ctx context.Context, rc aliDomainRecord) (recID string, err error) {
 	if c.schema == nil {
 		return "", errors.New("schema was not initialed proprely")
@@ -37,7 +96,7 @@ func (c *aliClient) addDomainRecord(ctx context.Context, rc aliDomainRecord) (re
 		rc.TTL = 600
 	}
 	c.SetAction("AddDomainRecord")
-	c.SetRequestBody("DomainName", rc.DomainName)
+	c.SetRequestBody("DomainName", c.domainName(rc.DomainName))
 	c.SetRequestBody("RR", rc.Rr)
 	c.SetRequestBody("Type", rc.DomainType)
 	c.SetRequestBody("Value", rc.DomainValue)
@@ -45,6 +104,9 @@ func (c *aliClient) addDomainRecord(ctx context.Context, rc aliDomainRecord) (re
 	if rc.Priority > 0 {
 		c.SetRequestBody("Priority", fmt.Sprintf("%d", max(rc.Priority, 50)))
 	}
+	if len(rc.Line) > 0 {
+		c.SetRequestBody("Line", rc.Line)
+	}
 	rs := aliDomainResult{}
 	err = c.doAPIRequest(ctx, &rs)
 	recID = rs.RecID
@@ -71,6 +133,23 @@ func (c *aliClient) delDomainRecord(ctx context.Context, rc aliDomainRecord) (re
 	return recID, err
 }
 
+func (c *aliClient) delSubDomainRecords(ctx context.Context, rr, name string, recType string) (int, error) {
+	if c.schema == nil {
+		return 0, errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("DeleteSubDomainRecords")
+	c.SetRequestBody("DomainName", c.domainName(name))
+	c.SetRequestBody("RR", rr)
+	if recType != "" {
+		c.SetRequestBody("Type", recType)
+	}
+	rs := aliDomainResult{}
+	err := c.doAPIRequest(ctx, &rs)
+	return rs.TotalCount, err
+}
+
 func (c *aliClient) setDomainRecord(ctx context.Context, rc aliDomainRecord) (recID string, err error) {
 	if c.schema == nil {
 		return "", errors.New("schema was not initialed proprely")
@@ -89,6 +168,9 @@ func (c *aliClient) setDomainRecord(ctx context.Context, rc aliDomainRecord) (re
 	if rc.Priority > 0 {
 		c.SetRequestBody("Priority", fmt.Sprintf("%d", max(rc.Priority, 50)))
 	}
+	if len(rc.Line) > 0 {
+		c.SetRequestBody("Line", rc.Line)
+	}
 	rs := aliDomainResult{}
 	err = c.doAPIRequest(ctx, &rs)
 	recID = rs.RecID
@@ -98,6 +180,85 @@ func (c *aliClient) setDomainRecord(ctx context.Context, rc aliDomainRecord) (re
 	return recID, err
 }
 
+func (c *aliClient) setDomainRecordRemark(ctx context.Context, recID, remark string) error {
+	if c.schema == nil {
+		return errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("UpdateDomainRecordRemark")
+	c.SetRequestBody("RecordId", recID)
+	if len(remark) > 0 {
+		c.SetRequestBody("Remark", remark)
+	}
+	rs := aliDomainResult{}
+	return c.doAPIRequest(ctx, &rs)
+}
+
+func (c *aliClient) setDNSSLBWeight(ctx context.Context, recID string, weight int) error {
+	if c.schema == nil {
+		return errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("UpdateDNSSLBWeight")
+	c.SetRequestBody("RecordId", recID)
+	c.SetRequestBody("Weight", fmt.Sprintf("%d", weight))
+	rs := aliDomainResult{}
+	return c.doAPIRequest(ctx, &rs)
+}
+
+func (c *aliClient) setDNSSLBStatus(ctx context.Context, subDomain, name string, recType string, open bool) error {
+	if c.schema == nil {
+		return errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("SetDNSSLBStatus")
+	c.SetRequestBody("SubDomain", strings.Trim(subDomain, "."))
+	c.SetRequestBody("DomainName", strings.Trim(name, "."))
+	if recType != "" {
+		c.SetRequestBody("Type", recType)
+	}
+	c.SetRequestBody("Open", fmt.Sprintf("%t", open))
+	rs := aliDomainResult{}
+	return c.doAPIRequest(ctx, &rs)
+}
+
+func (c *aliClient) queryDNSSLBSubDomains(ctx context.Context, name, rr string, pageNumber, pageSize int) (aliDomainResult, error) {
+	if c.schema == nil {
+		return aliDomainResult{}, errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("DescribeDNSSLBSubDomains")
+	c.SetRequestBody("DomainName", strings.Trim(name, "."))
+	if rr != "" {
+		c.SetRequestBody("Rr", rr)
+	}
+	c.SetRequestBody("PageNumber", fmt.Sprintf("%d", pageNumber))
+	c.SetRequestBody("PageSize", fmt.Sprintf("%d", pageSize))
+	rs := aliDomainResult{}
+	err := c.doAPIRequest(ctx, &rs)
+	if err != nil {
+		return aliDomainResult{}, err
+	}
+	return rs, err
+}
+
+func (c *aliClient) setDomainRecordStatus(ctx context.Context, recID, status string) error {
+	if c.schema == nil {
+		return errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("SetDomainRecordStatus")
+	c.SetRequestBody("RecordId", recID)
+	c.SetRequestBody("Status", status)
+	rs := aliDomainResult{}
+	return c.doAPIRequest(ctx, &rs)
+}
+
 func (c *aliClient) getDomainRecord(ctx context.Context, recID string) (aliDomainRecord, error) {
 	if c.schema == nil {
 		return aliDomainRecord{}, errors.New("schema was not initialed proprely")
@@ -115,20 +276,47 @@ func (c *aliClient) getDomainRecord(ctx context.Context, recID string) (aliDomai
 	return rec, err
 }
 
-func (c *aliClient) queryDomainRecords(ctx context.Context, name string) ([]aliDomainRecord, error) {
+func (c *aliClient) queryDomainRecords(ctx context.Context, name string, status string, pageNumber, pageSize int) (aliDomainResult, error) {
 	if c.schema == nil {
-		return nil, errors.New("schema was not initialed proprely")
+		return aliDomainResult{}, errors.New("schema was not initialed proprely")
 	}
 	c.Lock()
 	defer c.Unlock()
 	c.SetAction("DescribeDomainRecords")
 	c.SetRequestBody("DomainName", strings.Trim(name, "."))
+	if status != "" {
+		c.SetRequestBody("Status", status)
+	}
+	c.SetRequestBody("PageNumber", fmt.Sprintf("%d", pageNumber))
+	c.SetRequestBody("PageSize", fmt.Sprintf("%d", pageSize))
+	rs := aliDomainResult{}
+	err := c.doAPIRequest(ctx, &rs)
+	if err != nil {
+		return aliDomainResult{}, err
+	}
+	return rs, err
+}
+
+func (c *aliClient) querySubDomainRecords(ctx context.Context, subDomain, name string, recType string, pageNumber, pageSize int) (aliDomainResult, error) {
+	if c.schema == nil {
+		return aliDomainResult{}, errors.New("schema was not initialed proprely")
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.SetAction("DescribeSubDomainRecords")
+	c.SetRequestBody("SubDomain", strings.Trim(subDomain, "."))
+	c.SetRequestBody("DomainName", c.domainName(name))
+	if recType != "" {
+		c.SetRequestBody("Type", recType)
+	}
+	c.SetRequestBody("PageNumber", fmt.Sprintf("%d", pageNumber))
+	c.SetRequestBody("PageSize", fmt.Sprintf("%d", pageSize))
 	rs := aliDomainResult{}
 	err := c.doAPIRequest(ctx, &rs)
 	if err != nil {
-		return []aliDomainRecord{}, err
+		return aliDomainResult{}, err
 	}
-	return rs.DomainRecords.Record, err
+	return rs, err
 }
 
 func (c *aliClient) queryDomainRecord(ctx context.Context, rr, name string, recType string, recVal ...string) (aliDomainRecord, error) {
@@ -153,7 +341,7 @@ func (c *aliClient) queryDomainRecord(ctx context.Context, rr, name string, recT
 		return aliDomainRecord{}, err
 	}
 	if len(rs.DomainRecords.Record) == 0 {
-		return aliDomainRecord{}, errors.New("the Record Name of the domain not found")
+		return aliDomainRecord{}, fmt.Errorf("the Record Name of the domain %w", ErrNotFound)
 	}
 	return rs.DomainRecords.Record[0], err
 }
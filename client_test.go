@@ -8,13 +8,13 @@ import (
 )
 
 func Test_ClientAPIReq(t *testing.T) {
-	p0.getClient()
-	p0.client.SetRequestBody("Action", "DescribeDomainRecords")
-	p0.client.SetRequestBody("KeyWords", "vi")
+	cl, _ := p0.getClient(context.TODO())
+	cl.SetRequestBody("Action", "DescribeDomainRecords")
+	cl.SetRequestBody("KeyWords", "vi")
 	var rs aliDomaRecords
 	rspData := aliDomainResult{}
-	err := p0.client.doAPIRequest(context.TODO(), &rspData)
-	t.Log("req", p0.client.schema, "data", rspData, "err:", err, "rs:", rs)
+	err := cl.doAPIRequest(context.TODO(), &rspData)
+	t.Log("req", cl.schema, "data", rspData, "err:", err, "rs:", rs)
 }
 
 func Test_QueryDomainRecord(t *testing.T) {
@@ -62,4 +62,22 @@ func Test_SetDomainRecords(t *testing.T) {
 		Text: "I don't knows 23",
 	}})
 	t.Log("result:", recs, "err:", err)
-}
\ No newline at end of file
+}
+func Test_ListZones(t *testing.T) {
+	zones, err := p0.ListZones(context.TODO())
+	t.Log("result:", zones, "err:", err)
+
+	zones, err = p0.ListZonesWithFilter(context.TODO(), ZoneFilter{KeyWord: "viscrop"})
+	t.Log("result:", zones, "err:", err)
+}
+
+func Test_RecordPageSize(t *testing.T) {
+	cases := map[int]int{0: 500, -1: 500, 100: 100, 500: 500, 1000: 500}
+	for size, excepted := range cases {
+		p := Provider{RecordPageSize: size}
+		if got := p.recordPageSize(); got != excepted {
+			t.Log("excepted:", excepted, "got:", got)
+			t.Fail()
+		}
+	}
+}
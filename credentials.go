@@ -0,0 +1,287 @@
+package alidns
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	envAccessKeyID     = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	envAccessKeySecret = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	envSecurityToken   = "ALIBABA_CLOUD_SECURITY_TOKEN"
+	envRegionID        = "ALIBABA_CLOUD_REGION_ID"
+	envProfile         = "ALIBABA_CLOUD_PROFILE"
+	envCredentialsFile = "ALIBABA_CLOUD_CREDENTIALS_FILE"
+	envCLIConfigFile   = "ALIBABA_CLOUD_CONFIG_FILE"
+	defaultProfile     = "default"
+	// temporary credentials are refreshed once they will expire within the window
+	credentialRefreshWindow = 5 * time.Minute
+)
+
+// ErrNoCredentials is wrapped by the errors of credential providers which find no credentials
+var ErrNoCredentials = errors.New("no credentials found")
+
+// CredentialProvider provides the credential for signing requests,
+// implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	Retrieve(ctx context.Context) (CredentialInfo, error)
+}
+
+// CredentialChain retrieves the credential from the first provider which has one
+type CredentialChain []CredentialProvider
+
+// DefaultCredentialChain is used by Provider if its CredentialInfo is empty, it looks up
+// the environment variables, ~/.alibabacloud/credentials and ~/.aliyun/config.json in order,
+// then the OIDC role if $ALIBABA_CLOUD_OIDC_TOKEN_FILE is set (e.g. RRSA of ACK),
+// and the RAM role of the ECS instance if $ALIBABA_CLOUD_ECS_METADATA is set.
+// The profile defaults to $ALIBABA_CLOUD_PROFILE, or "default" if it is unset.
+func DefaultCredentialChain(profile string) CredentialChain {
+	result := CredentialChain{
+		EnvCredentials{},
+		ProfileCredentials{Profile: profile},
+		CLIConfigCredentials{Profile: profile},
+	}
+	if len(os.Getenv(envOIDCTokenFile)) > 0 {
+		result = append(result, &OIDCRoleCredentials{})
+	}
+	if len(os.Getenv(envECSMetadata)) > 0 {
+		result = append(result, &ECSRAMRoleCredentials{})
+	}
+	return result
+}
+
+// defaultChain keeps the DefaultCredentialChain of a Provider, so that the temporary
+// credentials in it are cached across requests. It is rebuilt if the profile changes.
+type defaultChain struct {
+	mutex   sync.Mutex
+	profile string
+	chain   CredentialChain
+}
+
+func (c *defaultChain) get(profile string) CredentialChain {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.chain == nil || c.profile != profile {
+		c.profile, c.chain = profile, DefaultCredentialChain(profile)
+	}
+	return c.chain
+}
+
+func (c CredentialChain) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	var errs = OpErrors("RetrieveCredential")
+	for _, p := range c {
+		cred, err := p.Retrieve(ctx)
+		if err == nil {
+			return cred, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			errs.JoinError(err)
+		}
+	}
+	if err := errs.Error(); err != nil {
+		return CredentialInfo{}, err
+	}
+	return CredentialInfo{}, fmt.Errorf("alidns: credential chain: %w", ErrNoCredentials)
+}
+
+// refreshingCredential caches a temporary credential until shortly before its expiration,
+// it is safe for concurrent use.
+type refreshingCredential struct {
+	mutex      sync.Mutex
+	cred       CredentialInfo
+	expiration time.Time
+}
+
+func (c *refreshingCredential) get(ctx context.Context, fetch func(ctx context.Context) (CredentialInfo, time.Time, error)) (CredentialInfo, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.cred.AccessKeyID) > 0 && time.Until(c.expiration) > credentialRefreshWindow {
+		return c.cred, nil
+	}
+	cred, expiration, err := fetch(ctx)
+	if err != nil {
+		return CredentialInfo{}, err
+	}
+	c.cred, c.expiration = cred, expiration
+	return cred, nil
+}
+
+// EnvCredentials reads the credential from ALIBABA_CLOUD_ACCESS_KEY_ID,
+// ALIBABA_CLOUD_ACCESS_KEY_SECRET and the optional ALIBABA_CLOUD_SECURITY_TOKEN.
+type EnvCredentials struct{}
+
+func (EnvCredentials) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	cred := CredentialInfo{
+		AccessKeyID:     strings.TrimSpace(os.Getenv(envAccessKeyID)),
+		AccessKeySecret: strings.TrimSpace(os.Getenv(envAccessKeySecret)),
+		SecurityToken:   strings.TrimSpace(os.Getenv(envSecurityToken)),
+		RegionID:        strings.TrimSpace(os.Getenv(envRegionID)),
+	}
+	if cred.AccessKeyID == "" || cred.AccessKeySecret == "" {
+		return CredentialInfo{}, fmt.Errorf("alidns: environment variables: %w", ErrNoCredentials)
+	}
+	return cred, nil
+}
+
+// ProfileCredentials reads the credential of the profile in the INI file of Alibaba Cloud SDKs,
+// only the profiles in type access_key and sts are supported.
+type ProfileCredentials struct {
+	// Optional name of the profile, the default is $ALIBABA_CLOUD_PROFILE or "default"
+	Profile string
+	// Optional path of the file, the default is $ALIBABA_CLOUD_CREDENTIALS_FILE or ~/.alibabacloud/credentials
+	Path string
+}
+
+func (c ProfileCredentials) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	path := firstNonEmpty(c.Path, os.Getenv(envCredentialsFile))
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return CredentialInfo{}, fmt.Errorf("alidns: credentials file: %w", ErrNoCredentials)
+		}
+		path = filepath.Join(home, ".alibabacloud", "credentials")
+	}
+	sections, err := readINIFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return CredentialInfo{}, fmt.Errorf("alidns: credentials file %s: %w", path, ErrNoCredentials)
+	}
+	if err != nil {
+		return CredentialInfo{}, err
+	}
+	profile := profileName(c.Profile)
+	section, ok := sections[profile]
+	if !ok {
+		return CredentialInfo{}, fmt.Errorf("alidns: profile %s in %s: %w", profile, path, ErrNoCredentials)
+	}
+	switch section["type"] {
+	case "", "access_key", "sts":
+	default:
+		return CredentialInfo{}, fmt.Errorf("alidns: unsupported type %s of profile %s in %s", section["type"], profile, path)
+	}
+	cred := CredentialInfo{
+		AccessKeyID:     section["access_key_id"],
+		AccessKeySecret: section["access_key_secret"],
+		SecurityToken:   firstNonEmpty(section["security_token"], section["sts_token"]),
+		RegionID:        section["region_id"],
+	}
+	if cred.AccessKeyID == "" || cred.AccessKeySecret == "" {
+		return CredentialInfo{}, fmt.Errorf("alidns: access_key_id or access_key_secret of profile %s in %s is missing", profile, path)
+	}
+	return cred, nil
+}
+
+// CLIConfigCredentials reads the credential of the profile in the config file of Alibaba Cloud CLI,
+// only the profiles in mode AK and StsToken are supported.
+type CLIConfigCredentials struct {
+	// Optional name of the profile, the default is $ALIBABA_CLOUD_PROFILE, or the current profile of the file
+	Profile string
+	// Optional path of the file, the default is $ALIBABA_CLOUD_CONFIG_FILE or ~/.aliyun/config.json
+	Path string
+}
+
+type cliConfig struct {
+	Current  string `json:"current"`
+	Profiles []struct {
+		Name            string `json:"name"`
+		Mode            string `json:"mode"`
+		AccessKeyID     string `json:"access_key_id"`
+		AccessKeySecret string `json:"access_key_secret"`
+		StsToken        string `json:"sts_token"`
+		RegionID        string `json:"region_id"`
+	} `json:"profiles"`
+}
+
+func (c CLIConfigCredentials) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	path := firstNonEmpty(c.Path, os.Getenv(envCLIConfigFile))
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return CredentialInfo{}, fmt.Errorf("alidns: CLI config file: %w", ErrNoCredentials)
+		}
+		path = filepath.Join(home, ".aliyun", "config.json")
+	}
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return CredentialInfo{}, fmt.Errorf("alidns: CLI config file %s: %w", path, ErrNoCredentials)
+	}
+	if err != nil {
+		return CredentialInfo{}, err
+	}
+	var config cliConfig
+	err = json.Unmarshal(buf, &config)
+	if err != nil {
+		return CredentialInfo{}, fmt.Errorf("alidns: CLI config file %s: %v", path, err)
+	}
+	profile := firstNonEmpty(c.Profile, os.Getenv(envProfile), config.Current, defaultProfile)
+	for _, el := range config.Profiles {
+		if el.Name != profile {
+			continue
+		}
+		switch el.Mode {
+		case "", "AK", "StsToken":
+		default:
+			return CredentialInfo{}, fmt.Errorf("alidns: unsupported mode %s of profile %s in %s", el.Mode, profile, path)
+		}
+		cred := CredentialInfo{
+			AccessKeyID:     el.AccessKeyID,
+			AccessKeySecret: el.AccessKeySecret,
+			SecurityToken:   el.StsToken,
+			RegionID:        el.RegionID,
+		}
+		if cred.AccessKeyID == "" || cred.AccessKeySecret == "" {
+			return CredentialInfo{}, fmt.Errorf("alidns: access_key_id or access_key_secret of profile %s in %s is missing", profile, path)
+		}
+		return cred, nil
+	}
+	return CredentialInfo{}, fmt.Errorf("alidns: profile %s in %s: %w", profile, path, ErrNoCredentials)
+}
+
+func profileName(profile string) string {
+	return firstNonEmpty(profile, os.Getenv(envProfile), defaultProfile)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// readINIFile parses the sections of the INI file into key-value maps
+func readINIFile(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	result := map[string]map[string]string{}
+	var section map[string]string
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = map[string]string{}
+			result[strings.TrimSpace(line[1:len(line)-1])] = section
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || section == nil {
+			return nil, fmt.Errorf("alidns: invalid line %d of %s", lineNum, path)
+		}
+		section[strings.TrimSpace(kv[0])] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+	}
+	return result, scanner.Err()
+}
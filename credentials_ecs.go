@@ -0,0 +1,115 @@
+package alidns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultECSMetadataURL = "http://100.100.100.200"
+	envECSMetadata        = "ALIBABA_CLOUD_ECS_METADATA"
+	ecsMetadataTokenTTL   = 21600
+)
+
+// ECSRAMRoleCredentials retrieves the temporary credential of the RAM role attached to
+// the ECS instance from the metadata service, and refreshes it shortly before expiration.
+// Requests to the metadata service are hardened with a session token, like IMDSv2.
+type ECSRAMRoleCredentials struct {
+	// Optional name of the RAM role, the default is $ALIBABA_CLOUD_ECS_METADATA,
+	// or the role attached to the instance
+	RoleName string
+	// Optional base URL of the metadata service, the default is http://100.100.100.200
+	MetadataURL string
+	// Optional HTTP client for requesting the metadata service, the default is http.DefaultClient
+	HTTPClient *http.Client
+
+	cache refreshingCredential
+}
+
+type ecsRoleCredential struct {
+	Code            string `json:"Code"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	AccessKeySecret string `json:"AccessKeySecret"`
+	SecurityToken   string `json:"SecurityToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+func (c *ECSRAMRoleCredentials) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	return c.cache.get(ctx, c.fetch)
+}
+
+func (c *ECSRAMRoleCredentials) fetch(ctx context.Context) (CredentialInfo, time.Time, error) {
+	token, err := c.request(ctx, http.MethodPut, "/latest/api/token", "")
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: get ECS metadata token: %v", err)
+	}
+	roleName := firstNonEmpty(c.RoleName, os.Getenv(envECSMetadata))
+	if roleName == "" {
+		roleName, err = c.request(ctx, http.MethodGet, "/latest/meta-data/ram/security-credentials/", token)
+		if err != nil {
+			return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: get RAM role of ECS instance: %v", err)
+		}
+		roleName = strings.TrimSpace(strings.SplitN(roleName, "\n", 2)[0])
+	}
+	if roleName == "" {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: ECS instance: %w", ErrNoCredentials)
+	}
+	buf, err := c.request(ctx, http.MethodGet, "/latest/meta-data/ram/security-credentials/"+roleName, token)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: get credential of RAM role %s: %v", roleName, err)
+	}
+	var rs ecsRoleCredential
+	err = json.Unmarshal([]byte(buf), &rs)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: parse credential of RAM role %s: %v", roleName, err)
+	}
+	if rs.Code != "Success" || rs.AccessKeyID == "" || rs.AccessKeySecret == "" {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: get credential of RAM role %s: %s", roleName, rs.Code)
+	}
+	expiration, err := time.Parse(time.RFC3339, rs.Expiration)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: parse expiration of RAM role %s: %v", roleName, err)
+	}
+	return CredentialInfo{
+		AccessKeyID:     rs.AccessKeyID,
+		AccessKeySecret: rs.AccessKeySecret,
+		SecurityToken:   rs.SecurityToken,
+	}, expiration, nil
+}
+
+// request sends the request to the metadata service, with the token if it is not empty
+func (c *ECSRAMRoleCredentials) request(ctx context.Context, method, path, token string) (string, error) {
+	baseURL := strings.TrimSuffix(firstNonEmpty(c.MetadataURL, defaultECSMetadataURL), "/")
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(token) > 0 {
+		req.Header.Set("X-aliyun-ecs-metadata-token", token)
+	} else {
+		req.Header.Set("X-aliyun-ecs-metadata-token-ttl-seconds", fmt.Sprintf("%d", ecsMetadataTokenTTL))
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	rsp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	buf, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return "", err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d: %s", rsp.StatusCode, strings.TrimSpace(string(buf)))
+	}
+	return string(buf), nil
+}
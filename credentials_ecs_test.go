@@ -0,0 +1,99 @@
+package alidns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestMetadataServer serves the credential of role "dns-role" which expires after the duration
+func newTestMetadataServer(t *testing.T, expiresIn time.Duration) (*httptest.Server, *int32) {
+	var fetches int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("X-aliyun-ecs-metadata-token-ttl-seconds") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("metadata-token"))
+	})
+	mux.HandleFunc("/latest/meta-data/ram/security-credentials/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-aliyun-ecs-metadata-token") != "metadata-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/latest/meta-data/ram/security-credentials/":
+			w.Write([]byte("dns-role"))
+		case "/latest/meta-data/ram/security-credentials/dns-role":
+			n := atomic.AddInt32(&fetches, 1)
+			json.NewEncoder(w).Encode(ecsRoleCredential{
+				Code:            "Success",
+				AccessKeyID:     "STS.id",
+				AccessKeySecret: "secret",
+				SecurityToken:   "token" + string(rune('0'+n)),
+				Expiration:      time.Now().Add(expiresIn).UTC().Format(time.RFC3339),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &fetches
+}
+
+func TestECSRAMRoleCredentials(t *testing.T) {
+	t.Setenv(envECSMetadata, "")
+	srv, fetches := newTestMetadataServer(t, time.Hour)
+	src := &ECSRAMRoleCredentials{MetadataURL: srv.URL}
+	for i := 0; i < 3; i++ {
+		cred, err := src.Retrieve(context.TODO())
+		if err != nil || cred.AccessKeyID != "STS.id" || cred.SecurityToken != "token1" {
+			t.Fatal("excepted the credential of the discovered role, got:", cred, err)
+		}
+	}
+	if n := atomic.LoadInt32(fetches); n != 1 {
+		t.Error("excepted the credential to be cached, got fetches:", n)
+	}
+
+	src = &ECSRAMRoleCredentials{RoleName: "other-role", MetadataURL: srv.URL}
+	if _, err := src.Retrieve(context.TODO()); err == nil {
+		t.Error("excepted error of unknown role")
+	}
+}
+
+func TestECSRAMRoleCredentialsRefresh(t *testing.T) {
+	srv, fetches := newTestMetadataServer(t, credentialRefreshWindow/2)
+	src := &ECSRAMRoleCredentials{RoleName: "dns-role", MetadataURL: srv.URL}
+	cred, err := src.Retrieve(context.TODO())
+	if err != nil || cred.SecurityToken != "token1" {
+		t.Fatal("excepted the first credential, got:", cred, err)
+	}
+	cred, err = src.Retrieve(context.TODO())
+	if err != nil || cred.SecurityToken != "token2" {
+		t.Error("excepted the credential to be refreshed before expiration, got:", cred, err)
+	}
+	if n := atomic.LoadInt32(fetches); n != 2 {
+		t.Error("excepted 2 fetches, got:", n)
+	}
+}
+
+func TestECSRAMRoleCredentialsProvider(t *testing.T) {
+	clearCredentialEnv(t)
+	srv, fetches := newTestMetadataServer(t, time.Hour)
+	p := Provider{CredentialProvider: &ECSRAMRoleCredentials{MetadataURL: srv.URL}}
+	for i := 0; i < 2; i++ {
+		cl, err := p.getClient(context.TODO())
+		if err != nil || cl.cred.SecurityToken != "token1" {
+			t.Fatal("excepted the client to be signed with the role credential, got:", err)
+		}
+	}
+	if n := atomic.LoadInt32(fetches); n != 1 {
+		t.Error("excepted 1 fetch, got:", n)
+	}
+}
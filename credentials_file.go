@@ -0,0 +1,63 @@
+package alidns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileCredentials reads the credential from a JSON file with the keys access_key_id,
+// access_key_secret and optional security_token and region_id, e.g. a mounted secret.
+// The file is loaded again once its modification time or size changes, so rotated keys
+// are used by the following requests. If the changed file is invalid, e.g. it is being
+// written, the credential loaded formerly is kept.
+type FileCredentials struct {
+	// Path of the credential file
+	Path string
+
+	mutex   sync.Mutex
+	cred    CredentialInfo
+	modTime time.Time
+	size    int64
+}
+
+func (c *FileCredentials) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.Path == "" {
+		return CredentialInfo{}, errors.New("alidns: path of the credential file is missing")
+	}
+	stat, err := os.Stat(c.Path)
+	if err != nil {
+		return c.loaded(err)
+	}
+	if len(c.cred.AccessKeyID) > 0 && stat.ModTime().Equal(c.modTime) && stat.Size() == c.size {
+		return c.cred, nil
+	}
+	buf, err := os.ReadFile(c.Path)
+	if err != nil {
+		return c.loaded(err)
+	}
+	var cred CredentialInfo
+	err = json.Unmarshal(buf, &cred)
+	if err != nil {
+		return c.loaded(fmt.Errorf("alidns: credential file %s: %v", c.Path, err))
+	}
+	if cred.AccessKeyID == "" || cred.AccessKeySecret == "" {
+		return c.loaded(fmt.Errorf("alidns: access_key_id or access_key_secret in %s is missing", c.Path))
+	}
+	c.cred, c.modTime, c.size = cred, stat.ModTime(), stat.Size()
+	return cred, nil
+}
+
+// loaded returns the credential loaded formerly if there is one, otherwise the error
+func (c *FileCredentials) loaded(err error) (CredentialInfo, error) {
+	if len(c.cred.AccessKeyID) > 0 {
+		return c.cred, nil
+	}
+	return CredentialInfo{}, err
+}
@@ -0,0 +1,53 @@
+package alidns
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFileCredentials(t *testing.T) {
+	path := writeTestFile(t, "credential.json", `{"access_key_id": "id1", "access_key_secret": "secret1"}`)
+	src := &FileCredentials{Path: path}
+	cred, err := src.Retrieve(context.TODO())
+	if err != nil || cred.AccessKeyID != "id1" {
+		t.Fatal("excepted the credential in file, got:", cred, err)
+	}
+
+	type testCase struct {
+		memo    string
+		content string
+		id      string
+	}
+
+	cases := []testCase{
+		{memo: "rotated key", content: `{"access_key_id": "id2", "access_key_secret": "secret2", "security_token": "token2"}`, id: "id2"},
+		{memo: "partially written file", content: `{"access_key_id": "id3",`, id: "id2"},
+		{memo: "missing secret", content: `{"access_key_id": "id3"}`, id: "id2"},
+		{memo: "rotated again", content: `{"access_key_id": "id4", "access_key_secret": "secret4"}`, id: "id4"},
+	}
+
+	modTime := time.Now()
+	for _, c := range cases {
+		if err := os.WriteFile(path, []byte(c.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		// make sure the modification time changes on file systems with coarse timestamps
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		cred, err := src.Retrieve(context.TODO())
+		if err != nil || cred.AccessKeyID != c.id {
+			t.Log("case", c.memo, "got:", cred, err)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+
+	if _, err := (&FileCredentials{Path: path + ".missing"}).Retrieve(context.TODO()); err == nil {
+		t.Error("excepted error of missing file")
+	}
+}
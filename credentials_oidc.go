@@ -0,0 +1,111 @@
+package alidns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envRoleArn         = "ALIBABA_CLOUD_ROLE_ARN"
+	envOIDCProviderArn = "ALIBABA_CLOUD_OIDC_PROVIDER_ARN"
+	envOIDCTokenFile   = "ALIBABA_CLOUD_OIDC_TOKEN_FILE"
+	envRoleSessionName = "ALIBABA_CLOUD_ROLE_SESSION_NAME"
+)
+
+// OIDCRoleCredentials retrieves the temporary credential of a RAM role by STS AssumeRoleWithOIDC,
+// e.g. for the pods in ACK clusters with RRSA enabled. The token file is read again every time
+// the role is assumed, so the rotated token is picked up, and the role is assumed again shortly
+// before the credential expires.
+type OIDCRoleCredentials struct {
+	// Optional ARN of the role to assume, the default is $ALIBABA_CLOUD_ROLE_ARN
+	RoleArn string
+	// Optional ARN of the OIDC provider, the default is $ALIBABA_CLOUD_OIDC_PROVIDER_ARN
+	OIDCProviderArn string
+	// Optional path of the OIDC token file, the default is $ALIBABA_CLOUD_OIDC_TOKEN_FILE
+	OIDCTokenFile string
+	// Optional name of the session, the default is $ALIBABA_CLOUD_ROLE_SESSION_NAME or "libdns-alidns"
+	RoleSessionName string
+	// Optional policy in JSON to further restrict the permissions of the session
+	Policy string
+	// Optional duration of the session, the default is 1 hour
+	Duration time.Duration
+	// Optional host or URL of the STS API, the default is sts.aliyuncs.com
+	Endpoint string
+	// Optional HTTP client for requesting the STS API, the default is http.DefaultClient
+	HTTPClient *http.Client
+
+	cache refreshingCredential
+}
+
+func (c *OIDCRoleCredentials) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	return c.cache.get(ctx, c.fetch)
+}
+
+func (c *OIDCRoleCredentials) fetch(ctx context.Context) (CredentialInfo, time.Time, error) {
+	roleArn := firstNonEmpty(c.RoleArn, os.Getenv(envRoleArn))
+	providerArn := firstNonEmpty(c.OIDCProviderArn, os.Getenv(envOIDCProviderArn))
+	tokenFile := firstNonEmpty(c.OIDCTokenFile, os.Getenv(envOIDCTokenFile))
+	if roleArn == "" || providerArn == "" || tokenFile == "" {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: OIDC role: %w", ErrNoCredentials)
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: read OIDC token file: %v", err)
+	}
+	params := url.Values{}
+	params.Set("RoleArn", roleArn)
+	params.Set("OIDCProviderArn", providerArn)
+	params.Set("OIDCToken", strings.TrimSpace(string(token)))
+	params.Set("RoleSessionName", firstNonEmpty(c.RoleSessionName, os.Getenv(envRoleSessionName), defaultRoleSessionName))
+	params.Set("DurationSeconds", strconv.Itoa(roleSessionSeconds(c.Duration)))
+	if len(c.Policy) > 0 {
+		params.Set("Policy", c.Policy)
+	}
+	rs := stsCredentialResult{}
+	err = c.request(ctx, "AssumeRoleWithOIDC", params, &rs)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: assume role %s with OIDC: %w", roleArn, err)
+	}
+	return rs.credential()
+}
+
+// request sends the request of the anonymous action to the STS API, which is not signed
+func (c *OIDCRoleCredentials) request(ctx context.Context, action string, params url.Values, result interface{}) error {
+	apiHost, err := apiEndpoint(firstNonEmpty(c.Endpoint, defaultSTSEndpoint))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiHost, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("x-acs-action", action)
+	req.Header.Set("x-acs-version", stsAPIVersion)
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	rsp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	buf, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return apiErrorOf(rsp.StatusCode, buf)
+	}
+	return json.Unmarshal(buf, result)
+}
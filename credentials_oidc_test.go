@@ -0,0 +1,78 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+const testOIDCProviderArn = "acs:ram::1234567890:oidc-provider/ack-rrsa-c123"
+
+func TestOIDCRoleCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	p, srv := fakeProvider(t)
+	tokenFile := writeTestFile(t, "token", srv.OIDCToken())
+	t.Setenv(envRoleArn, testRoleArn)
+	t.Setenv(envOIDCProviderArn, testOIDCProviderArn)
+	t.Setenv(envOIDCTokenFile, tokenFile)
+	src := &OIDCRoleCredentials{Endpoint: srv.URL, HTTPClient: srv.Client()}
+	p.CredentialInfo = CredentialInfo{}
+	p.CredentialProvider = src
+
+	if _, err := p.GetRecords(context.TODO(), "example.com."); err != nil {
+		t.Fatal("excepted records got with the OIDC role credential, got:", err)
+	}
+	if _, err := p.GetRecords(context.TODO(), "example.com."); err != nil {
+		t.Fatal("excepted records got with the cached credential, got:", err)
+	}
+	if n := srv.Sessions(testRoleArn); n != 1 {
+		t.Error("excepted the role credential to be cached, got sessions:", n)
+	}
+
+	// the rotated token is read when the role is assumed again
+	rotated := srv.RotateOIDCToken()
+	if err := os.WriteFile(tokenFile, []byte(rotated), 0600); err != nil {
+		t.Fatal(err)
+	}
+	src.cache.mutex.Lock()
+	src.cache.expiration = time.Now().Add(credentialRefreshWindow / 2)
+	src.cache.mutex.Unlock()
+	if _, err := p.GetRecords(context.TODO(), "example.com."); err != nil {
+		t.Fatal("excepted records got with the rotated token, got:", err)
+	}
+	if n := srv.Sessions(testRoleArn); n != 2 {
+		t.Error("excepted the role to be assumed again, got sessions:", n)
+	}
+}
+
+func TestOIDCRoleCredentialsErrors(t *testing.T) {
+	clearCredentialEnv(t)
+	_, srv := fakeProvider(t)
+	src := &OIDCRoleCredentials{Endpoint: srv.URL, HTTPClient: srv.Client()}
+	if _, err := src.Retrieve(context.TODO()); !errors.Is(err, ErrNoCredentials) {
+		t.Error("excepted ErrNoCredentials, got:", err)
+	}
+
+	src = &OIDCRoleCredentials{
+		RoleArn:         testRoleArn,
+		OIDCProviderArn: testOIDCProviderArn,
+		OIDCTokenFile:   writeTestFile(t, "token", "expired-token"),
+		Endpoint:        srv.URL,
+		HTTPClient:      srv.Client(),
+	}
+	_, err := src.Retrieve(context.TODO())
+	if apiErr := (*APIError)(nil); !errors.As(err, &apiErr) || apiErr.Code != "AuthenticationFail.OIDCToken.Invalid" {
+		t.Error("excepted AuthenticationFail.OIDCToken.Invalid, got:", err)
+	}
+}
+
+func TestDefaultCredentialChainOIDC(t *testing.T) {
+	clearCredentialEnv(t)
+	t.Setenv(envOIDCTokenFile, "token")
+	chain := DefaultCredentialChain("")
+	if _, ok := chain[len(chain)-1].(*OIDCRoleCredentials); !ok {
+		t.Error("excepted OIDCRoleCredentials in the chain, got:", chain)
+	}
+}
@@ -0,0 +1,128 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSTSEndpoint        = "sts.aliyuncs.com"
+	stsAPIVersion             = "2015-04-01"
+	defaultRoleSessionName    = "libdns-alidns"
+	defaultRoleSessionSeconds = 3600
+)
+
+// AssumeRoleCredentials retrieves the temporary credential of a RAM role by STS AssumeRole
+// with a base credential, and assumes the role again shortly before the credential expires.
+type AssumeRoleCredentials struct {
+	// The base credential which is allowed to assume the role
+	Credential CredentialInfo
+	// Optional source of the base credential, which is used if Credential is empty
+	Source CredentialProvider
+	// ARN of the role to assume, e.g. acs:ram::123456789012****:role/dns-admin
+	RoleArn string
+	// Optional name of the session, the default is "libdns-alidns"
+	RoleSessionName string
+	// Optional policy in JSON to further restrict the permissions of the session
+	Policy string
+	// Optional duration of the session between 15 minutes and the max session duration of the role,
+	// the default is 1 hour
+	Duration time.Duration
+	// Optional host or URL of the STS API, the default is sts.aliyuncs.com
+	Endpoint string
+	// Optional HTTP client for requesting the STS API, the default is http.DefaultClient
+	HTTPClient *http.Client
+
+	cache refreshingCredential
+}
+
+type stsCredentialResult struct {
+	ReqID       string `json:"RequestId"`
+	Credentials struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		AccessKeySecret string `json:"AccessKeySecret"`
+		SecurityToken   string `json:"SecurityToken"`
+		Expiration      string `json:"Expiration"`
+	} `json:"Credentials"`
+}
+
+// credential converts the result to the temporary credential and its expiration
+func (r stsCredentialResult) credential() (CredentialInfo, time.Time, error) {
+	if r.Credentials.AccessKeyID == "" || r.Credentials.AccessKeySecret == "" {
+		return CredentialInfo{}, time.Time{}, errors.New("alidns: empty credential in the result of STS")
+	}
+	expiration, err := time.Parse(time.RFC3339, r.Credentials.Expiration)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: parse expiration of STS credential: %v", err)
+	}
+	return CredentialInfo{
+		AccessKeyID:     r.Credentials.AccessKeyID,
+		AccessKeySecret: r.Credentials.AccessKeySecret,
+		SecurityToken:   r.Credentials.SecurityToken,
+	}, expiration, nil
+}
+
+func (c *AssumeRoleCredentials) Retrieve(ctx context.Context) (CredentialInfo, error) {
+	return c.cache.get(ctx, c.fetch)
+}
+
+func (c *AssumeRoleCredentials) fetch(ctx context.Context) (CredentialInfo, time.Time, error) {
+	if c.RoleArn == "" {
+		return CredentialInfo{}, time.Time{}, errors.New("alidns: RoleArn to assume is missing")
+	}
+	base := c.Credential
+	if base.AccessKeyID == "" && base.AccessKeySecret == "" && c.Source != nil {
+		var err error
+		base, err = c.Source.Retrieve(ctx)
+		if err != nil {
+			return CredentialInfo{}, time.Time{}, err
+		}
+	}
+	cl, err := stsClient(&base, c.Endpoint, c.HTTPClient)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, err
+	}
+	cl.SetAction("AssumeRole")
+	cl.SetRequestBody("RoleArn", c.RoleArn)
+	cl.SetRequestBody("RoleSessionName", firstNonEmpty(c.RoleSessionName, defaultRoleSessionName))
+	cl.SetRequestBody("DurationSeconds", strconv.Itoa(roleSessionSeconds(c.Duration)))
+	if len(c.Policy) > 0 {
+		cl.SetRequestBody("Policy", c.Policy)
+	}
+	rs := stsCredentialResult{}
+	err = cl.doAPIRequest(ctx, &rs)
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, fmt.Errorf("alidns: assume role %s: %w", c.RoleArn, err)
+	}
+	cred, expiration, err := rs.credential()
+	if err != nil {
+		return CredentialInfo{}, time.Time{}, err
+	}
+	cred.RegionID = base.RegionID
+	return cred, expiration, nil
+}
+
+// stsClient returns the client for the STS API signed with the credential
+func stsClient(cred *CredentialInfo, endpoint string, httpClient *http.Client) (*aliClient, error) {
+	cl, err := getClient(cred)
+	if err != nil {
+		return cl, err
+	}
+	cl.schema.APIHost, err = apiEndpoint(firstNonEmpty(endpoint, defaultSTSEndpoint))
+	if err != nil {
+		return cl, err
+	}
+	cl.httpClient = httpClient
+	return cl, cl.SetVersion(stsAPIVersion)
+}
+
+func roleSessionSeconds(duration time.Duration) int {
+	if duration <= 0 {
+		return defaultRoleSessionSeconds
+	}
+	return int(duration / time.Second)
+}
@@ -0,0 +1,77 @@
+package alidns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+const testRoleArn = "acs:ram::1234567890:role/dns-admin"
+
+func TestAssumeRoleCredentials(t *testing.T) {
+	p, srv := fakeProvider(t)
+	src := &AssumeRoleCredentials{
+		Credential: p.CredentialInfo,
+		RoleArn:    testRoleArn,
+		Endpoint:   srv.URL,
+		HTTPClient: srv.Client(),
+	}
+	p.CredentialInfo = CredentialInfo{}
+	p.CredentialProvider = src
+
+	_, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+	})
+	if err != nil {
+		t.Fatal("excepted records appended with the role credential, got:", err)
+	}
+	if _, err = p.GetRecords(context.TODO(), "example.com."); err != nil {
+		t.Fatal("excepted records got with the role credential, got:", err)
+	}
+	if n := srv.Sessions(testRoleArn); n != 1 {
+		t.Error("excepted the role credential to be cached, got sessions:", n)
+	}
+
+	// the credential is assumed again once it will expire soon
+	src.cache.mutex.Lock()
+	src.cache.expiration = time.Now().Add(credentialRefreshWindow / 2)
+	src.cache.mutex.Unlock()
+	if _, err = p.GetRecords(context.TODO(), "example.com."); err != nil {
+		t.Fatal("excepted records got with the refreshed credential, got:", err)
+	}
+	if n := srv.Sessions(testRoleArn); n != 2 {
+		t.Error("excepted the role to be assumed again, got sessions:", n)
+	}
+}
+
+func TestAssumeRoleCredentialsErrors(t *testing.T) {
+	_, srv := fakeProvider(t)
+	type testCase struct {
+		memo string
+		src  *AssumeRoleCredentials
+	}
+
+	cases := []testCase{
+		{memo: "missing role", src: &AssumeRoleCredentials{}},
+		{memo: "invalid role", src: &AssumeRoleCredentials{RoleArn: "dns-admin"}},
+		{memo: "invalid duration", src: &AssumeRoleCredentials{RoleArn: testRoleArn, Duration: time.Minute}},
+		{memo: "wrong base credential", src: &AssumeRoleCredentials{RoleArn: testRoleArn,
+			Credential: CredentialInfo{AccessKeyID: srv.AccessKeyID, AccessKeySecret: "wrong"}}},
+	}
+
+	for _, c := range cases {
+		if c.src.Credential.AccessKeyID == "" {
+			c.src.Credential = CredentialInfo{AccessKeyID: srv.AccessKeyID, AccessKeySecret: srv.AccessKeySecret}
+		}
+		c.src.Endpoint = srv.URL
+		c.src.HTTPClient = srv.Client()
+		if _, err := c.src.Retrieve(context.TODO()); err == nil {
+			t.Log("case", c.memo, "excepted error")
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
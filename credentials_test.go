@@ -0,0 +1,149 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func clearCredentialEnv(t *testing.T) {
+	for _, k := range []string{envAccessKeyID, envAccessKeySecret, envSecurityToken, envRegionID, envProfile,
+		envRoleArn, envOIDCProviderArn, envOIDCTokenFile, envECSMetadata} {
+		t.Setenv(k, "")
+	}
+	t.Setenv(envCredentialsFile, filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv(envCLIConfigFile, filepath.Join(t.TempDir(), "config.json"))
+}
+
+const testCredentialsFile = `
+# comment
+[default]
+type = access_key
+access_key_id = default_id
+access_key_secret = default_secret
+
+[sts]
+type = sts
+access_key_id = sts_id
+access_key_secret = sts_secret
+security_token = sts_token
+
+[ecs]
+type = ecs_ram_role
+role_name = role
+`
+
+const testCLIConfigFile = `{
+	"current": "dev",
+	"profiles": [
+		{"name": "default", "mode": "AK", "access_key_id": "cli_default_id", "access_key_secret": "cli_default_secret"},
+		{"name": "dev", "mode": "StsToken", "access_key_id": "cli_dev_id", "access_key_secret": "cli_dev_secret", "sts_token": "cli_dev_token", "region_id": "cn-shanghai"}
+	]
+}`
+
+func TestEnvCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	if _, err := (EnvCredentials{}).Retrieve(context.TODO()); !errors.Is(err, ErrNoCredentials) {
+		t.Error("excepted ErrNoCredentials, got:", err)
+	}
+	t.Setenv(envAccessKeyID, "env_id")
+	t.Setenv(envAccessKeySecret, "env_secret")
+	t.Setenv(envSecurityToken, "env_token")
+	cred, err := (EnvCredentials{}).Retrieve(context.TODO())
+	if err != nil || cred.AccessKeyID != "env_id" || cred.AccessKeySecret != "env_secret" || cred.SecurityToken != "env_token" {
+		t.Error("unexcepted credential:", cred, err)
+	}
+}
+
+func TestProfileCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	path := writeTestFile(t, "credentials", testCredentialsFile)
+	type testCase struct {
+		memo    string
+		profile string
+		envProf string
+		id      string
+		token   string
+		noCred  bool
+		fails   bool
+	}
+
+	cases := []testCase{
+		{memo: "default profile", id: "default_id"},
+		{memo: "profile from field", profile: "sts", id: "sts_id", token: "sts_token"},
+		{memo: "profile from env", envProf: "sts", id: "sts_id", token: "sts_token"},
+		{memo: "missing profile", profile: "none", noCred: true},
+		{memo: "unsupported type", profile: "ecs", fails: true},
+	}
+
+	for _, c := range cases {
+		t.Setenv(envProfile, c.envProf)
+		cred, err := ProfileCredentials{Profile: c.profile, Path: path}.Retrieve(context.TODO())
+		ok := cred.AccessKeyID == c.id && cred.SecurityToken == c.token &&
+			errors.Is(err, ErrNoCredentials) == c.noCred &&
+			(err != nil) == (c.fails || c.noCred)
+		if !ok {
+			t.Log("case", c.memo, "got:", cred, err)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestCLIConfigCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	path := writeTestFile(t, "config.json", testCLIConfigFile)
+	cred, err := CLIConfigCredentials{Path: path}.Retrieve(context.TODO())
+	if err != nil || cred.AccessKeyID != "cli_dev_id" || cred.SecurityToken != "cli_dev_token" || cred.RegionID != "cn-shanghai" {
+		t.Error("excepted the current profile, got:", cred, err)
+	}
+	cred, err = CLIConfigCredentials{Profile: "default", Path: path}.Retrieve(context.TODO())
+	if err != nil || cred.AccessKeyID != "cli_default_id" {
+		t.Error("excepted the default profile, got:", cred, err)
+	}
+}
+
+func TestDefaultCredentialChain(t *testing.T) {
+	clearCredentialEnv(t)
+	if _, err := DefaultCredentialChain("").Retrieve(context.TODO()); !errors.Is(err, ErrNoCredentials) {
+		t.Error("excepted ErrNoCredentials, got:", err)
+	}
+
+	t.Setenv(envCLIConfigFile, writeTestFile(t, "config.json", testCLIConfigFile))
+	p := Provider{}
+	cred, err := p.credential(context.TODO())
+	if err != nil || cred.AccessKeyID != "cli_dev_id" {
+		t.Error("excepted the credential of CLI config, got:", cred, err)
+	}
+
+	t.Setenv(envCredentialsFile, writeTestFile(t, "credentials", testCredentialsFile))
+	p.Profile = "sts"
+	cred, err = p.credential(context.TODO())
+	if err != nil || cred.AccessKeyID != "sts_id" {
+		t.Error("excepted the credential of credentials file, got:", cred, err)
+	}
+
+	t.Setenv(envAccessKeyID, "env_id")
+	t.Setenv(envAccessKeySecret, "env_secret")
+	cred, err = p.credential(context.TODO())
+	if err != nil || cred.AccessKeyID != "env_id" {
+		t.Error("excepted the credential of environment variables, got:", cred, err)
+	}
+
+	p.AccessKeyID, p.AccessKeySecret = "static_id", "static_secret"
+	cred, err = p.credential(context.TODO())
+	if err != nil || cred.AccessKeyID != "static_id" {
+		t.Error("excepted the static credential, got:", cred, err)
+	}
+}
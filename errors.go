@@ -0,0 +1,169 @@
+package alidns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// ErrNotFound is wrapped by the errors of zones or records which cannot be found
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidRecord is wrapped by the errors of records which are rejected before sending any request
+var ErrInvalidRecord = errors.New("invalid record")
+
+// ErrLocked is wrapped by the errors of locked records, which cannot be changed or deleted
+var ErrLocked = errors.New("record is locked")
+
+// APIError is the error responded by the Alidns API
+type APIError struct {
+	// HTTP status of the response
+	StatusCode int
+	// Error code, e.g. Throttling.User or DomainRecordDuplicate
+	Code string
+	// Error message
+	Message string
+	// ID of the request, which is useful when asking Aliyun's support for help
+	RequestID string
+	// ID of the host which served the request
+	HostID string
+	// Diagnosis URL recommended by the API
+	Recommend string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("get error status: HTTP %d: ", e.StatusCode)
+	if len(e.Code) > 0 {
+		msg += e.Code + ": "
+	}
+	msg += e.Message
+	if len(e.RequestID) > 0 {
+		msg += " (RequestId: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// apiErrorOf decodes the error responded by the API in the HTTP status and body,
+// the fields missing in the body are left empty.
+func apiErrorOf(status int, body []byte) *APIError {
+	rs := aliDomainResult{}
+	_ = json.Unmarshal(body, &rs)
+	return &APIError{
+		StatusCode: status,
+		Code:       rs.Code,
+		Message:    rs.Msg,
+		RequestID:  rs.ReqID,
+		HostID:     rs.HostID,
+		Recommend:  rs.Rcmd,
+	}
+}
+
+// Is reports whether target is an *APIError with the same Code,
+// so errors.Is(err, &APIError{Code: "DomainRecordDuplicate"}) works as excepted.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok || len(t.Code) == 0 {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+func (e *APIError) hasCode(fn func(s, substr string) bool, codes ...string) bool {
+	for _, code := range codes {
+		if fn(e.Code, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordError is the error caused at a record when operating records in batch
+type RecordError struct {
+	Record libdns.Record
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return "caused at record named '" + e.Record.RR().Name + "': " + e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// LockedRecordError is the error of a locked record which was left alone when operating records in batch
+type LockedRecordError struct {
+	// The live record which is locked
+	Record libdns.Record
+}
+
+func (e *LockedRecordError) Error() string {
+	rr := e.Record.RR()
+	return "record named '" + rr.Name + "' of type " + rr.Type + " with value '" + rr.Data + "' is locked"
+}
+
+func (e *LockedRecordError) Unwrap() error {
+	return ErrLocked
+}
+
+// IsNotFound reports whether the error is caused by a zone or record which does not exist,
+// errors of unknown credentials like InvalidAccessKeyId.NotFound are reported by IsAuth instead.
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || IsAuth(err) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusNotFound ||
+		apiErr.hasCode(strings.HasSuffix, "NotExist", "NoExist", "NotFound", "NotBelongToUser")
+}
+
+// IsThrottled reports whether the error is caused by exceeding the request quota
+func IsThrottled(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests ||
+		apiErr.hasCode(strings.HasPrefix, "Throttling")
+}
+
+// IsAuth reports whether the error is caused by invalid credentials or missing permissions
+func IsAuth(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized ||
+		apiErr.StatusCode == http.StatusForbidden ||
+		apiErr.hasCode(strings.HasPrefix,
+			"InvalidAccessKeyId", "InvalidSecurityToken", "SignatureDoesNotMatch",
+			"IncompleteSignature", "Forbidden", "NoPermission")
+}
+
+// IsDuplicate reports whether the error is caused by a record which already exists
+func IsDuplicate(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.hasCode(strings.Contains, "Duplicate")
+}
+
+// IsLocked reports whether the error is caused by a locked record
+func IsLocked(err error) bool {
+	if errors.Is(err, ErrLocked) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.hasCode(strings.Contains, "Locked")
+}
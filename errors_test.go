@@ -0,0 +1,110 @@
+package alidns
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func TestAPIErrorClassifiers(t *testing.T) {
+	type testCase struct {
+		memo      string
+		err       error
+		notFound  bool
+		throttled bool
+		auth      bool
+		duplicate bool
+		locked    bool
+	}
+
+	cases := []testCase{
+		{memo: "throttled", err: &APIError{StatusCode: 400, Code: "Throttling.User"}, throttled: true},
+		{memo: "too many requests", err: &APIError{StatusCode: 429}, throttled: true},
+		{memo: "invalid access key", err: &APIError{StatusCode: 404, Code: "InvalidAccessKeyId.NotFound"}, auth: true},
+		{memo: "signature mismatch", err: &APIError{StatusCode: 400, Code: "SignatureDoesNotMatch"}, auth: true},
+		{memo: "forbidden", err: &APIError{StatusCode: 403, Code: "Forbidden.RAM"}, auth: true},
+		{memo: "duplicate record", err: &APIError{StatusCode: 400, Code: "DomainRecordDuplicate"}, duplicate: true},
+		{memo: "record not found", err: &APIError{StatusCode: 400, Code: "DomainRecordNotBelongToUser"}, notFound: true},
+		{memo: "zone not found", err: fmt.Errorf("cannot found specified zone example.com: %w", ErrNotFound), notFound: true},
+		{memo: "wrapped by op errors", err: OpErrors("op").JoinRecord(libdns.RR{Name: "rec"}, &APIError{Code: "Throttling"}).Error(), throttled: true},
+		{memo: "locked record", err: &APIError{StatusCode: 400, Code: "DomainRecordLocked"}, locked: true},
+		{memo: "locked record error", err: OpErrors("op").JoinError(&LockedRecordError{Record: libdns.RR{Name: "rec"}}).Error(), locked: true},
+		{memo: "plain error", err: errors.New("something wrong")},
+	}
+
+	for _, c := range cases {
+		ok := IsNotFound(c.err) == c.notFound &&
+			IsThrottled(c.err) == c.throttled &&
+			IsAuth(c.err) == c.auth &&
+			IsDuplicate(c.err) == c.duplicate &&
+			IsLocked(c.err) == c.locked
+		if !ok {
+			t.Log("case", c.memo, "got unexcepted classification of:", c.err)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestOpErrorsUnwrap(t *testing.T) {
+	apiErr := &APIError{StatusCode: 400, Code: "DomainRecordDuplicate", Message: "The DNS record already exists.", RequestID: "req"}
+	err := OpErrors("AppendRecords").
+		JoinRecord(libdns.RR{Name: "www"}, apiErr).
+		JoinRecord(libdns.RR{Name: "mail"}, errors.New("something wrong")).
+		Error()
+
+	var recErr *RecordError
+	if !errors.As(err, &recErr) || recErr.Record.RR().Name != "www" {
+		t.Error("excepted the RecordError of www, got:", recErr)
+	}
+	var got *APIError
+	if !errors.As(err, &got) || got.RequestID != "req" {
+		t.Error("excepted the APIError, got:", got)
+	}
+	if !errors.Is(err, &APIError{Code: "DomainRecordDuplicate"}) {
+		t.Error("excepted errors.Is to match the code")
+	}
+	const excepted = "there is something error when 'AppendRecords': " +
+		"caused at record named 'www': get error status: HTTP 400: DomainRecordDuplicate: The DNS record already exists. (RequestId: req)," +
+		"caused at record named 'mail': something wrong"
+	if err.Error() != excepted {
+		t.Log("excepted:", excepted, "got:", err.Error())
+		t.Fail()
+	}
+}
+
+func Test_apiErrorOf(t *testing.T) {
+	type testCase struct {
+		memo   string
+		status int
+		body   string
+		result APIError
+	}
+
+	cases := []testCase{
+		{
+			memo:   "error of the API",
+			status: 400,
+			body:   `{"RequestId":"req-1","HostId":"alidns.aliyuncs.com","Code":"DomainRecordDuplicate","Message":"The DNS record already exists.","Recommend":"https://api.aliyun.com/troubleshoot"}`,
+			result: APIError{StatusCode: 400, Code: "DomainRecordDuplicate", Message: "The DNS record already exists.", RequestID: "req-1", HostID: "alidns.aliyuncs.com", Recommend: "https://api.aliyun.com/troubleshoot"},
+		},
+		{
+			memo:   "body which is not JSON",
+			status: 502,
+			body:   "<html>Bad Gateway</html>",
+			result: APIError{StatusCode: 502},
+		},
+	}
+
+	for _, c := range cases {
+		if got := apiErrorOf(c.status, []byte(c.body)); *got != c.result {
+			t.Log("case", c.memo, "excepted:", c.result, "got:", *got)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
@@ -0,0 +1,87 @@
+package alidns
+
+import (
+	"context"
+	"fmt"
+)
+
+const defaultLine = "default"
+
+// Line is a resolution line which records can be served on, e.g. the ISP or region of clients
+type Line struct {
+	// Code of the line, which is set in ProviderData.Line, e.g. default, telecom or oversea
+	Code string `json:"LineCode"`
+	// Name of the line
+	Name string `json:"LineName"`
+	// Optional code of the parent line, e.g. telecom of cn_telecom_beijing
+	ParentCode string `json:"FatherCode,omitempty"`
+}
+
+type aliRecordLines struct {
+	RecordLine []Line `json:"RecordLine,omitempty"`
+}
+
+// lineOf returns the line of records, where the empty line is the default one
+func lineOf(line string) string {
+	if line == "" {
+		return defaultLine
+	}
+	return line
+}
+
+// ListLines lists the lines which the records of the zone can be served on,
+// which depend on the instance edition of the zone.
+func (p *Provider) ListLines(ctx context.Context, zone string) ([]Line, error) {
+	lines, err := p.zoneLines(ctx, zone)
+	if err != nil {
+		return nil, OpError("ListLines", err)
+	}
+	return append([]Line{}, lines...), nil
+}
+
+// zoneLines looks up the lines supported by the zone, from the cache if possible
+func (p *Provider) zoneLines(ctx context.Context, zone string) ([]Line, error) {
+	info, err := p.zoneInfo(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	if info.Lines != nil {
+		return info.Lines, nil
+	}
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := cl.querySupportLines(ctx, info.DomainName)
+	if err != nil {
+		return nil, err
+	}
+	p.zones.setLines(zone, lines)
+	return lines, nil
+}
+
+// validateLine checks whether the line of the record is supported by the zone,
+// DescribeSupportLines is only requested for records on lines other than the default one.
+func (p *Provider) validateLine(ctx context.Context, zone string, rc aliDomainRecord) error {
+	if lineOf(rc.Line) == defaultLine {
+		return nil
+	}
+	lines, err := p.zoneLines(ctx, zone)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if line.Code == rc.Line {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: line %s is not supported by zone %s", ErrInvalidRecord, rc.Line, zoneKey(zone))
+}
+
+// validateLines validates the line of the set, and returns the index of the first record in the input if it is invalid
+func (p *Provider) validateLines(ctx context.Context, zone string, set *rrSet) (int, error) {
+	if len(set.records) == 0 {
+		return 0, nil
+	}
+	return set.indexes[0], p.validateLine(ctx, zone, set.records[0])
+}
@@ -0,0 +1,142 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func lineRecord(ip, line string) libdns.Record {
+	return libdns.Address{Name: "www", TTL: 600 * time.Second, IP: netip.MustParseAddr(ip),
+		ProviderData: ProviderData{Line: line}}
+}
+
+func TestListLines(t *testing.T) {
+	p, srv := fakeProvider(t)
+	srv.AddZone(alidnstest.Zone{DomainName: "example.org", VersionCode: alidnstest.EditionEnterpriseBasic,
+		CustomLines: []string{"office"}})
+	cases := []struct {
+		memo     string
+		zone     string
+		line     string
+		excepted bool
+	}{
+		{memo: "basic line", zone: "example.com.", line: "telecom", excepted: true},
+		{memo: "enterprise line of free zone", zone: "example.com.", line: "cn_telecom_beijing", excepted: false},
+		{memo: "enterprise line", zone: "example.org.", line: "cn_telecom_beijing", excepted: true},
+		{memo: "custom line", zone: "example.org.", line: "office", excepted: true},
+	}
+	for _, c := range cases {
+		lines, err := p.ListLines(context.TODO(), c.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, line := range lines {
+			found = found || line.Code == c.line
+		}
+		if found != c.excepted {
+			t.Errorf("excepted line %s found %v, got: %v", c.line, c.excepted, lines)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+	if n := srv.Requests("DescribeSupportLines"); n != 2 {
+		t.Error("excepted the lines of zones cached, got requests:", n)
+	}
+}
+
+func TestUnsupportedLine(t *testing.T) {
+	p, srv := fakeProvider(t)
+	_, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{lineRecord("192.0.2.1", "os_asia")})
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Error("excepted ErrInvalidRecord of the unsupported line, got:", err)
+	}
+	_, err = p.SetRecords(context.TODO(), "example.com.", []libdns.Record{lineRecord("192.0.2.1", "os_asia")})
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Error("excepted ErrInvalidRecord of the unsupported line, got:", err)
+	}
+	for _, action := range []string{"AddDomainRecord", "DescribeSubDomainRecords"} {
+		if n := srv.Requests(action); n != 0 {
+			t.Errorf("excepted no %s requests, got: %d", action, n)
+		}
+	}
+	// no lookup of lines for records on the default line
+	if _, err = p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{lineRecord("192.0.2.1", "")}); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Requests("DescribeSupportLines"); n != 1 {
+		t.Error("excepted 1 DescribeSupportLines request, got:", n)
+	}
+}
+
+func TestSetRecordsByLine(t *testing.T) {
+	p, srv := fakeProvider(t)
+	for _, rec := range []alidnstest.Record{
+		{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.1"},
+		{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.2", Line: "telecom"},
+		{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.3", Line: "unicom"},
+	} {
+		if _, err := srv.AddRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, err := p.SetRecords(context.TODO(), "example.com.", []libdns.Record{
+		lineRecord("198.51.100.1", ""),
+		lineRecord("198.51.100.2", "telecom"),
+		lineRecord("198.51.100.3", "telecom"),
+	})
+	if err != nil || len(recs) != 3 {
+		t.Fatal("excepted 3 records set, got:", recs, err)
+	}
+	excepted := map[string]string{
+		"198.51.100.1": "default",
+		"198.51.100.2": "telecom",
+		"198.51.100.3": "telecom",
+		"192.0.2.3":    "unicom",
+	}
+	live := srv.Records("example.com")
+	if len(live) != len(excepted) {
+		t.Fatal("excepted records on the unicom line left alone, got:", live)
+	}
+	for _, rec := range live {
+		if excepted[rec.Value] != rec.Line {
+			t.Errorf("excepted %s on line %s, got: %s", rec.Value, excepted[rec.Value], rec.Line)
+		}
+	}
+}
+
+func TestDeleteRecordsByLine(t *testing.T) {
+	p, srv := fakeProvider(t)
+	for _, line := range []string{"default", "telecom", "unicom"} {
+		if _, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.1", Line: line}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		memo     string
+		rec      libdns.Record
+		deleted  int
+		excepted int
+	}{
+		{memo: "telecom line", rec: lineRecord("192.0.2.1", "telecom"), deleted: 1, excepted: 2},
+		{memo: "default line", rec: lineRecord("192.0.2.1", "default"), deleted: 1, excepted: 1},
+		{memo: "any line", rec: libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"}, deleted: 1, excepted: 0},
+	}
+	for _, c := range cases {
+		recs, err := p.DeleteRecords(context.TODO(), "example.com.", []libdns.Record{c.rec})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if live := srv.Records("example.com"); len(recs) != c.deleted || len(live) != c.excepted {
+			t.Errorf("excepted %d deleted and %d left, got: %v, %v", c.deleted, c.excepted, recs, live)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
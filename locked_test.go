@@ -0,0 +1,150 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func lockedRecords(t *testing.T, srv *alidnstest.Server) string {
+	var lockedID string
+	for _, rec := range []alidnstest.Record{
+		{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.1", Locked: true},
+		{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.2"},
+		{DomainName: "example.com", RR: "api", Type: "A", Value: "192.0.2.3"},
+	} {
+		added, err := srv.AddRecord(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added.Locked {
+			lockedID = added.RecordID
+		}
+	}
+	return lockedID
+}
+
+// lockedErrors returns the values of the locked records reported by the error
+func lockedErrors(err error) []string {
+	var result []string
+	var multi interface{ Unwrap() []error }
+	if !errors.As(err, &multi) {
+		return nil
+	}
+	for _, e := range multi.Unwrap() {
+		var lockedErr *LockedRecordError
+		if errors.As(e, &lockedErr) {
+			result = append(result, lockedErr.Record.RR().Data)
+		}
+	}
+	return result
+}
+
+func TestSetRecordsLocked(t *testing.T) {
+	cases := []struct {
+		memo     string
+		failFast bool
+		set      int
+		excepted []string
+	}{
+		{memo: "skip locked records", failFast: false, set: 2, excepted: []string{"192.0.2.1", "198.51.100.2", "198.51.100.3"}},
+		{memo: "fail fast", failFast: true, set: 0, excepted: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}},
+	}
+	for _, c := range cases {
+		p, srv := fakeProvider(t)
+		p.FailFastOnLocked = c.failFast
+		lockedRecords(t, srv)
+		recs, err := p.SetRecords(context.TODO(), "example.com.", []libdns.Record{
+			libdns.RR{Name: "www", Type: "A", Data: "198.51.100.2"},
+			libdns.RR{Name: "api", Type: "A", Data: "198.51.100.3"},
+		})
+		if !IsLocked(err) || len(recs) != c.set {
+			t.Errorf("excepted %d records set with locked error, got: %v, %v", c.set, recs, err)
+			continue
+		}
+		if locked := lockedErrors(err); len(locked) != 1 || locked[0] != "192.0.2.1" {
+			t.Error("excepted the locked record reported, got:", locked)
+			continue
+		}
+		live := srv.Records("example.com")
+		ok := len(live) == len(c.excepted)
+		for i := 0; ok && i < len(live); i++ {
+			ok = live[i].Value == c.excepted[i]
+		}
+		if !ok {
+			t.Error("excepted the live records", c.excepted, "got:", live)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestDeleteRecordsLocked(t *testing.T) {
+	cases := []struct {
+		memo     string
+		failFast bool
+		byID     bool
+		deleted  int
+		excepted int
+	}{
+		{memo: "skip locked records", failFast: false, deleted: 1, excepted: 2},
+		{memo: "fail fast", failFast: true, deleted: 0, excepted: 3},
+		{memo: "skip locked record by ID", failFast: false, byID: true, deleted: 1, excepted: 2},
+		{memo: "fail fast on locked record by ID", failFast: true, byID: true, deleted: 0, excepted: 3},
+	}
+	for _, c := range cases {
+		p, srv := fakeProvider(t)
+		p.FailFastOnLocked = c.failFast
+		lockedID := lockedRecords(t, srv)
+		in := []libdns.Record{libdns.RR{Name: "www", Type: "A"}}
+		if c.byID {
+			in = []libdns.Record{
+				DomainRecord{ID: lockedID, Name: "www", Type: "A", Value: "192.0.2.1"},
+				libdns.RR{Name: "api", Type: "A"},
+			}
+		}
+		recs, err := p.DeleteRecords(context.TODO(), "example.com.", in)
+		if !IsLocked(err) || len(recs) != c.deleted {
+			t.Errorf("excepted %d records deleted with locked error, got: %v, %v", c.deleted, recs, err)
+			continue
+		}
+		if locked := lockedErrors(err); len(locked) != 1 || locked[0] != "192.0.2.1" {
+			t.Error("excepted the locked record reported, got:", locked)
+			continue
+		}
+		if live := srv.Records("example.com"); len(live) != c.excepted {
+			t.Errorf("excepted %d records left, got: %v", c.excepted, live)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestDisableRecordsLocked(t *testing.T) {
+	p, srv := fakeProvider(t)
+	lockedRecords(t, srv)
+	recs, err := p.DisableRecords(context.TODO(), "example.com.", []libdns.Record{libdns.RR{Name: "www", Type: "A"}})
+	if !errors.Is(err, ErrLocked) || len(recs) != 1 {
+		t.Error("excepted 1 record disabled with locked error, got:", recs, err)
+	}
+}
+
+func TestDeleteGotRecordsLocked(t *testing.T) {
+	p, srv := fakeProvider(t)
+	p.FailFastOnLocked = true
+	lockedRecords(t, srv)
+	recs, err := p.GetRecords(context.TODO(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := p.DeleteRecords(context.TODO(), "example.com.", recs)
+	if !IsLocked(err) || len(deleted) != 0 {
+		t.Error("excepted no records deleted with locked error, got:", deleted, err)
+	}
+	if n := srv.Requests("DeleteDomainRecord"); n != 0 {
+		t.Error("excepted no DeleteDomainRecord requests, got:", n)
+	}
+}
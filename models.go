@@ -1,7 +1,6 @@
 package alidns
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -44,6 +43,11 @@ func (r DomainRecord) RR() libdns.RR {
 	if r.Priority > 0 {
 		result.Data = fmt.Sprintf("%d %v", r.Priority, r.Value)
 	}
+	if r.Type == "CAA" {
+		if value, err := caaValue(r.Value); err == nil {
+			result.Data = value
+		}
+	}
 	return result
 }
 
@@ -59,6 +63,9 @@ type aliDomainRecord struct {
 	TTL         ttl_t  `json:"TTL,omitempty"`
 	Weight      int    `json:"Weight,omitempty"`
 	Priority    ttl_t  `json:"Priority,omitempty"`
+	Remark      string `json:"Remark,omitempty"`
+	CreateTime  int64  `json:"CreateTimestamp,omitempty"`
+	UpdateTime  int64  `json:"UpdateTimestamp,omitempty"`
 }
 
 func (r aliDomainRecord) DomainRecord() DomainRecord {
@@ -80,6 +87,22 @@ func (r aliDomainRecord) Equals(v aliDomainRecord) bool {
 	return result
 }
 
+func (r aliDomainRecord) sameData(v aliDomainRecord) bool {
+	return v.DomainValue == r.DomainValue && v.Priority == r.Priority
+}
+
+// matches reports whether the live record v matches r as a query,
+// empty type, value, priority, TTL or line of the query match anything.
+func (r aliDomainRecord) matches(v aliDomainRecord) bool {
+	result := strings.EqualFold(v.Rr, r.Rr)
+	result = result && (r.DomainType == "" || v.DomainType == r.DomainType)
+	result = result && (r.DomainValue == "" || v.DomainValue == r.DomainValue)
+	result = result && (r.Priority == 0 || v.Priority == r.Priority)
+	result = result && (r.TTL == 0 || v.TTL == r.TTL)
+	result = result && (r.Line == "" || lineOf(v.Line) == r.Line)
+	return result
+}
+
 type aliDomaRecords struct {
 	Record []aliDomainRecord `json:"Record,omitempty"`
 }
@@ -94,6 +117,17 @@ func (e instanceEdition) IsEnterpriseEdition() bool {
 	return result
 }
 
+// ttlOf returns the TTL which the instance edition actually accepts
+func (e instanceEdition) ttlOf(ttl ttl_t) ttl_t {
+	if ttl <= 0 {
+		return 600
+	}
+	if !e.IsEnterpriseEdition() {
+		return min(ttl, 600)
+	}
+	return ttl
+}
+
 const (
 	VersionPrefix             = "version_"
 	EditionEnterpriseAdvanced = instanceEdition(VersionPrefix + "enterprise_advanced")
@@ -103,38 +137,63 @@ const (
 )
 
 type aliDomainInfo struct {
-	DomainName  string          `json:"DomainName,omitempty"`
-	VersionCode instanceEdition `json:"VersionCode,omitempty"`
+	DomainID        string          `json:"DomainId,omitempty"`
+	DomainName      string          `json:"DomainName,omitempty"`
+	PunyCode        string          `json:"PunyCode,omitempty"`
+	GroupID         string          `json:"GroupId,omitempty"`
+	ResourceGroupID string          `json:"ResourceGroupId,omitempty"`
+	VersionCode     instanceEdition `json:"VersionCode,omitempty"`
+}
+
+func (d aliDomainInfo) Zone() libdns.Zone {
+	return libdns.Zone{Name: d.DomainName + "."}
 }
 
 type aliDomains struct {
 	Domain []aliDomainInfo `json:"Domain,omitempty"`
 }
 
+// ZoneFilter narrows the zones returned by ListZonesWithFilter
+type ZoneFilter struct {
+	// Optional ID of the domain group which zones belong to
+	GroupID string `json:"group_id,omitempty"`
+	// Optional ID of the resource group which zones belong to
+	ResourceGroupID string `json:"resource_group_id,omitempty"`
+	// Optional keyword which zone names are fuzzy matched with
+	KeyWord string `json:"keyword,omitempty"`
+}
+
 type aliDomainResult struct {
-	ReqID         string         `json:"RequestId,omitempty"`
-	DomainRecords aliDomaRecords `json:"DomainRecords,omitempty"`
-	Domains       aliDomains     `json:"Domains,omitempty"`
-	DomainLevel   int            `json:"DomainLevel,omitempty"`
-	DomainValue   string         `json:"Value,omitempty"`
-	DomainName    string         `json:"DomainName,omitempty"`
-	DomainType    string         `json:"Type,omitempty"`
-	Rr            string         `json:"RR,omitempty"`
-	TTL           ttl_t          `json:"TTL,omitempty"`
-	Msg           string         `json:"Message,omitempty"`
-	Rcmd          string         `json:"Recommend,omitempty"`
-	HostID        string         `json:"HostId,omitempty"`
-	Code          string         `json:"Code,omitempty"`
-	TotalCount    int            `json:"TotalCount,omitempty"`
-	PgSize        int            `json:"PageSize,omitempty"`
-	PgNum         int            `json:"PageNumber,omitempty"`
-	RecID         string         `json:"RecordId,omitempty"`
-	Line          string         `json:"Line,omitempty"`
-	Status        string         `json:"Status,omitempty"`
-	Locked        bool           `json:"Locked,omitempty"`
-	Weight        int            `json:"Weight,omitempty"`
-	MinTTL        int            `json:"MinTtl,omitempty"`
-	Priority      ttl_t          `json:"Priority,omitempty"`
+	ReqID         string           `json:"RequestId,omitempty"`
+	DomainRecords aliDomaRecords   `json:"DomainRecords,omitempty"`
+	Domains       aliDomains       `json:"Domains,omitempty"`
+	DomainLevel   int              `json:"DomainLevel,omitempty"`
+	DomainValue   string           `json:"Value,omitempty"`
+	DomainName    string           `json:"DomainName,omitempty"`
+	DomainType    string           `json:"Type,omitempty"`
+	Rr            string           `json:"RR,omitempty"`
+	TTL           ttl_t            `json:"TTL,omitempty"`
+	Msg           string           `json:"Message,omitempty"`
+	Rcmd          string           `json:"Recommend,omitempty"`
+	HostID        string           `json:"HostId,omitempty"`
+	Code          string           `json:"Code,omitempty"`
+	TotalCount    int              `json:"TotalCount,omitempty"`
+	PgSize        int              `json:"PageSize,omitempty"`
+	PgNum         int              `json:"PageNumber,omitempty"`
+	RecID         string           `json:"RecordId,omitempty"`
+	Line          string           `json:"Line,omitempty"`
+	Status        string           `json:"Status,omitempty"`
+	Locked        bool             `json:"Locked,omitempty"`
+	Weight        int              `json:"Weight,omitempty"`
+	MinTTL        int              `json:"MinTtl,omitempty"`
+	Priority      ttl_t            `json:"Priority,omitempty"`
+	Remark        string           `json:"Remark,omitempty"`
+	RecordLines   aliRecordLines   `json:"RecordLines,omitempty"`
+	SLBSubDomains aliSLBSubDomains `json:"SlbSubDomains,omitempty"`
+	Open          bool             `json:"Open,omitempty"`
+	RecordCount   int              `json:"RecordCount,omitempty"`
+	CreateTime    int64            `json:"CreateTimestamp,omitempty"`
+	UpdateTime    int64            `json:"UpdateTimestamp,omitempty"`
 }
 
 func (r *aliDomainResult) ToDomaRecord() aliDomainRecord {
@@ -150,6 +209,9 @@ func (r *aliDomainResult) ToDomaRecord() aliDomainRecord {
 		Locked:      r.Locked,
 		Weight:      r.Weight,
 		Priority:    r.Priority,
+		Remark:      r.Remark,
+		CreateTime:  r.CreateTime,
+		UpdateTime:  r.UpdateTime,
 	}
 }
 
@@ -167,15 +229,26 @@ func alidnsRecord(r libdns.Record, zone ...string) aliDomainRecord {
 	} else {
 		result.Rr = tmpRR.Name
 	}
-	result.DomainType = tmpRR.Type
+	result.DomainType = strings.ToUpper(tmpRR.Type)
 	result.DomainValue = tmpRR.Data
 	result.TTL = ttl_t(tmpRR.TTL.Seconds())
+	if result.DomainType == "MX" {
+		if priority, value, ok := splitPriority(result.DomainValue); ok {
+			result.Priority, result.DomainValue = priority, value
+		}
+	}
 	if svcb, svcbok := r.(libdns.ServiceBinding); svcbok {
-		result.Priority = ttl_t(svcb.Priority)
-		result.DomainValue = fmt.Sprintf("%s %s", svcb.Target, svcb.Params)
+		result.DomainValue = serviceBindingValue(svcb)
+	} else if result.DomainType == "HTTPS" || result.DomainType == "SVCB" {
+		if parsed, err := tmpRR.Parse(); err == nil {
+			result.DomainValue = serviceBindingValue(parsed.(libdns.ServiceBinding))
+		}
 	}
-	if rec, ok := r.(DomainRecord); ok {
-		result.RecordID = rec.ID
+	if data, ok := providerDataOf(r); ok {
+		result.RecordID = data.RecordID
+		result.Line = data.Line
+		result.Weight = data.Weight
+		result.Remark = data.Remark
 	}
 	return result
 }
@@ -184,6 +257,21 @@ type opErrors struct {
 	Op            string
 	length        uint64
 	errMsgBuilder strings.Builder
+	errs          []error
+}
+
+// opError is the error of an operation, which wraps the errors caused in it
+type opError struct {
+	msg  string
+	errs []error
+}
+
+func (e *opError) Error() string {
+	return e.msg
+}
+
+func (e *opError) Unwrap() []error {
+	return e.errs
 }
 
 func OpErrors(op string) *opErrors {
@@ -205,6 +293,7 @@ func (e *opErrors) JoinError(err error) *opErrors {
 		msg := "caused with: "
 		msg += err.Error()
 		e.errMsgBuilder.WriteString(msg + ",")
+		e.errs = append(e.errs, err)
 		e.length += 1
 	}
 	return e
@@ -212,11 +301,9 @@ func (e *opErrors) JoinError(err error) *opErrors {
 
 func (e *opErrors) JoinRecord(record libdns.Record, err error) *opErrors {
 	if err != nil {
-		msg := "caused at record named "
-		msg += "'" + record.RR().Name + "'"
-		msg += ": "
-		msg += err.Error()
-		e.errMsgBuilder.WriteString(msg + ",")
+		recErr := &RecordError{Record: record, Err: err}
+		e.errMsgBuilder.WriteString(recErr.Error() + ",")
+		e.errs = append(e.errs, recErr)
 		e.length += 1
 	}
 	return e
@@ -233,7 +320,7 @@ func (e *opErrors) errorMsg() string {
 
 func (e *opErrors) Error() error {
 	if e.length > 0 {
-		return errors.New(e.errorMsg())
+		return &opError{msg: e.errorMsg(), errs: e.errs}
 	}
 	return nil
 }
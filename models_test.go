@@ -86,8 +86,7 @@ func Test_aliDomainRecord(t *testing.T) {
 			result: aliDomainRecord{
 				Rr:          "sub",
 				DomainType:  "HTTPS",
-				Priority:    100,
-				DomainValue: "target.com alpn=333",
+				DomainValue: "100 target.com alpn=333",
 			},
 		},
 	}
@@ -218,3 +217,57 @@ func TestInstanceEdition(t *testing.T) {
 		t.Log("case ", c.memo, "was pass.")
 	}
 }
+
+func TestDomainInfoZone(t *testing.T) {
+	info := aliDomainInfo{DomainName: "example.com"}
+	if zone := info.Zone(); zone.Name != "example.com." {
+		t.Log("excepted:", "example.com.", "got:", zone.Name)
+		t.Fail()
+	}
+}
+
+func Test_aliDomainRecordMatches(t *testing.T) {
+	type testCase struct {
+		memo   string
+		query  aliDomainRecord
+		result bool
+	}
+
+	live := aliDomainRecord{Rr: "www", DomainType: "A", DomainValue: "1.1.1.1", TTL: 600}
+	cases := []testCase{
+		{
+			memo:   "exact match",
+			query:  aliDomainRecord{Rr: "www", DomainType: "A", DomainValue: "1.1.1.1", TTL: 600},
+			result: true,
+		},
+		{
+			memo:   "empty type, value and TTL match anything",
+			query:  aliDomainRecord{Rr: "www"},
+			result: true,
+		},
+		{
+			memo:   "name is not fuzzy matched",
+			query:  aliDomainRecord{Rr: "ww"},
+			result: false,
+		},
+		{
+			memo:   "different value",
+			query:  aliDomainRecord{Rr: "www", DomainValue: "1.1.1.11"},
+			result: false,
+		},
+		{
+			memo:   "different TTL",
+			query:  aliDomainRecord{Rr: "www", TTL: 1200},
+			result: false,
+		},
+	}
+
+	for _, c := range cases {
+		if c.query.matches(live) != c.result {
+			t.Log("case", c.memo, "excepted:", c.result, "got:", !c.result)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
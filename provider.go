@@ -2,14 +2,56 @@ package alidns
 
 import (
 	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/libdns/libdns"
 )
 
 // Provider implements the libdns interfaces for Alicloud.
+//
+// All methods of Provider are safe for concurrent use, every request is sent
+// with its own client and no request-scoped state is kept on the Provider.
+// Once the Provider is in use, CredentialInfo should only be replaced by SetCredential.
 type Provider struct {
-	client *aliClient
 	CredentialInfo
+	// Optional page size when querying the records of a zone, the default and maximum is 500
+	RecordPageSize int `json:"record_page_size,omitempty"`
+	// Optional number of record pages fetched in parallel once the total count is known,
+	// the default is 1 which fetches pages one by one
+	RecordPageConcurrency int `json:"record_page_concurrency,omitempty"`
+	// Optional duration for caching the metadata of zones, the default is 10 minutes,
+	// and a negative value disables the cache
+	ZoneCacheTTL time.Duration `json:"zone_cache_ttl,omitempty"`
+	// Optional policy for retrying requests failed with throttling or transient errors
+	RetryPolicy RetryPolicy `json:"retry_policy,omitempty"`
+	// Optional host or URL of the API endpoint, the default is https://alidns.aliyuncs.com/,
+	// e.g. alidns.ap-southeast-1.aliyuncs.com or http://127.0.0.1:8080/
+	Endpoint string `json:"endpoint,omitempty"`
+	// Optional HTTP client for sending requests, the default is http.DefaultClient
+	HTTPClient *http.Client `json:"-"`
+	// Optional profile of DefaultCredentialChain, which is used if AccessKeyID and
+	// AccessKeySecret are empty, the default is $ALIBABA_CLOUD_PROFILE or "default"
+	Profile string `json:"profile,omitempty"`
+	// Optional source of credentials instead of DefaultCredentialChain,
+	// which is used if AccessKeyID and AccessKeySecret are empty
+	CredentialProvider CredentialProvider `json:"-"`
+	// Optional switch for excluding the disabled records from GetRecords,
+	// which are included by default with Status "DISABLE" in their ProviderData
+	ExcludeDisabledRecords bool `json:"exclude_disabled_records,omitempty"`
+	// Optional switch for enabling the disabled records which SetRecords keeps, so the records set
+	// are answered, by default they are kept disabled, e.g. the ones paused by operators on purpose
+	EnableDisabledRecords bool `json:"enable_disabled_records,omitempty"`
+	// Optional switch for failing SetRecords, DeleteRecords, EnableRecords and DisableRecords
+	// before any change once a locked record would be changed, by default locked records
+	// are left alone and reported by LockedRecordError while the others are changed
+	FailFastOnLocked bool `json:"fail_fast_on_locked,omitempty"`
+
+	zones     zoneCache
+	chain     defaultChain
+	credMutex sync.RWMutex
 }
 
 // AppendRecords adds records to the zone. It returns the records that were added.
@@ -18,43 +60,181 @@ func (p *Provider) AppendRecords(ctx context.Context, zone string, recs []libdns
 	var errs = OpErrors("AppendRecords")
 	for _, rec := range recs {
 		ar := alidnsRecord(rec, zone)
+		err := validateRecord(&ar)
+		if err == nil {
+			err = p.validateLine(ctx, zone, ar)
+		}
+		if err != nil {
+			errs.JoinRecord(rec, err)
+			continue
+		}
 		rid, err := p.addDomainRecord(ctx, ar)
 		if err != nil {
 			errs.JoinRecord(rec, err)
 			continue
 		}
 		ar.RecordID = rid
-		rls = append(rls, ar.DomainRecord())
+		added := ar
+		added.Remark, added.Weight = "", 0
+		added, err = p.setRecordMeta(ctx, ar, added)
+		rls = append(rls, added.libdnsRecord())
+		if err != nil {
+			errs.JoinRecord(rec, err)
+		}
 	}
 	return rls, errs.Error()
 }
 
 // DeleteRecords deletes the records from the zone. If a record does not have an ID,
-// it will be looked up. It returns the records that were deleted.
+// the records exactly matching its name, type, value, TTL and line will be looked up,
+// where empty type, value, TTL or line match anything. Locked records are left alone
+// and reported by LockedRecordError, or fail the whole call before any deletion
+// if FailFastOnLocked is set. It returns the records that were deleted.
 func (p *Provider) DeleteRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
 	var rls []libdns.Record
 	var errs = OpErrors("DeleteRecords")
-	for _, rec := range recs {
-		ar := alidnsRecord(rec, zone)
-		if ar.RecordID == "" {
-			r0, err := p.queryDomainRecord(ctx, ar.Rr, ar.DomainName, ar.DomainType, ar.DomainValue)
+	cl, err := p.getClientWithZone(ctx, zone)
+	if err != nil {
+		return nil, OpError("DeleteRecords", err)
+	}
+	targets, ok := p.resolveTargets(ctx, cl, zone, recs, errs)
+	if !ok {
+		return nil, errs.Error()
+	}
+	for _, target := range targets {
+		_, err := p.delDomainRecord(ctx, target.record)
+		if err != nil {
+			target.joinError(errs, err)
+			continue
+		}
+		rls = append(rls, target.record.libdnsRecord())
+	}
+	return rls, errs.Error()
+}
+
+// DeleteSubDomainRecords deletes all the records with the name in the zone across all lines,
+// or only the ones of the type if it is not empty, by a single request. Locked records are
+// handled as DeleteRecords does, where the others are deleted one by one if any is locked.
+// It returns the records that existed beforehand.
+func (p *Provider) DeleteSubDomainRecords(ctx context.Context, zone, name, recType string) ([]libdns.Record, error) {
+	var errs = OpErrors("DeleteSubDomainRecords")
+	recType = strings.ToUpper(recType)
+	rr := libdns.RelativeName(libdns.AbsoluteName(name, strings.Trim(zone, ".")), strings.Trim(zone, "."))
+	live, err := p.querySubDomainRecords(ctx, zone, rr, recType)
+	if err != nil {
+		return nil, OpError("DeleteSubDomainRecords", err)
+	}
+	var rls []libdns.Record
+	var unlocked []aliDomainRecord
+	for _, rec := range live {
+		if rec.Locked {
+			errs.JoinError(&LockedRecordError{Record: rec.libdnsRecord()})
+			continue
+		}
+		unlocked = append(unlocked, rec)
+	}
+	switch {
+	case len(unlocked) == 0 || len(unlocked) < len(live) && p.FailFastOnLocked:
+		return nil, errs.Error()
+	case len(unlocked) < len(live):
+		for _, rec := range unlocked {
+			_, err = p.delDomainRecord(ctx, rec)
 			if err != nil {
-				errs.JoinRecord(rec, err)
+				recordTarget{input: rec.libdnsRecord(), record: rec}.joinError(errs, err)
 				continue
 			}
-			ar.RecordID = r0.RecordID
+			rls = append(rls, rec.libdnsRecord())
 		}
-		_, err := p.delDomainRecord(ctx, ar)
+		return rls, errs.Error()
+	}
+	cl, err := p.getClientWithZone(ctx, zone)
+	if err != nil {
+		return nil, OpError("DeleteSubDomainRecords", err)
+	}
+	_, err = cl.delSubDomainRecords(ctx, rr, zone, recType)
+	if err != nil {
+		return nil, OpError("DeleteSubDomainRecords", err)
+	}
+	for _, rec := range live {
+		rls = append(rls, rec.libdnsRecord())
+	}
+	return rls, nil
+}
+
+// recordTarget is a live record resolved from the input record
+type recordTarget struct {
+	input  libdns.Record
+	record aliDomainRecord
+}
+
+// joinError joins the error caused at the target, errors of locked records are joined as LockedRecordError
+func (t recordTarget) joinError(errs *opErrors, err error) {
+	if IsLocked(err) {
+		errs.JoinError(&LockedRecordError{Record: t.record.libdnsRecord()})
+		return
+	}
+	errs.JoinRecord(t.input, err)
+}
+
+// resolveTargets resolves the live records of the input records without duplicates,
+// the locked ones are joined to errs and excluded. It reports false if any record
+// is locked and FailFastOnLocked is set.
+func (p *Provider) resolveTargets(ctx context.Context, cl *aliClient, zone string, recs []libdns.Record, errs *opErrors) ([]recordTarget, bool) {
+	var result []recordTarget
+	seen := map[string]bool{}
+	locked := false
+	for _, rec := range recs {
+		live, err := p.resolveRecords(ctx, cl, zone, rec)
 		if err != nil {
 			errs.JoinRecord(rec, err)
 			continue
 		}
-		rls = append(rls, ar.DomainRecord())
+		for _, r0 := range live {
+			if seen[r0.RecordID] {
+				continue
+			}
+			seen[r0.RecordID] = true
+			if r0.Locked {
+				errs.JoinError(&LockedRecordError{Record: r0.libdnsRecord()})
+				locked = true
+				continue
+			}
+			result = append(result, recordTarget{input: rec, record: r0})
+		}
 	}
-	return rls, errs.Error()
+	return result, !locked || !p.FailFastOnLocked
+}
+
+// resolveRecords returns the live record of the ID if the record has one, otherwise the live records
+// exactly matching its name, type, value, TTL and line, where empty ones match anything.
+// Records with IDs are looked up as well, so the ones locked are known before any change.
+func (p *Provider) resolveRecords(ctx context.Context, cl *aliClient, zone string, rec libdns.Record) ([]aliDomainRecord, error) {
+	ar := alidnsRecord(rec, zone)
+	if ar.RecordID != "" {
+		live, err := p.getDomainRecord(ctx, ar.RecordID)
+		if err != nil {
+			return nil, err
+		}
+		return []aliDomainRecord{live}, nil
+	}
+	if ar.TTL > 0 {
+		ar.TTL = cl.ttlOf(ar.TTL)
+	}
+	live, err := p.querySubDomainRecords(ctx, zone, ar.Rr, ar.DomainType)
+	if err != nil {
+		return nil, err
+	}
+	var result []aliDomainRecord
+	for _, r0 := range live {
+		if ar.matches(r0) {
+			result = append(result, r0)
+		}
+	}
+	return result, nil
 }
 
-// GetRecords lists all the records in the zone.
+// GetRecords lists all the records in the zone, the disabled ones are
+// excluded if ExcludeDisabledRecords of the Provider is set.
 func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
 	var rls []libdns.Record
 	recs, err := p.queryDomainRecords(ctx, zone)
@@ -62,114 +242,479 @@ func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record
 		return nil, OpError("GetRecords", err)
 	}
 	for _, rec := range recs {
-		rls = append(rls, rec.DomainRecord())
+		rls = append(rls, rec.libdnsRecord())
 	}
 	return rls, nil
 }
 
-// SetRecords sets the records in the zone, either by updating existing records
-// or creating new ones. It returns the updated records.
+// SetRecords sets the records in the zone, so that for each (name, type, line)
+// in the input, the records of it in the zone are exactly the ones in the input,
+// where the line is the default one unless it is set in ProviderData.
+// Existing records are updated in place to keep their IDs, missing
+// ones are added and extra ones are deleted. Unchanged records are left alone,
+// disabled records are kept disabled unless EnableDisabledRecords is set.
+// Locked records which would be updated or deleted are left alone and reported
+// by LockedRecordError, or fail the whole call before any change if FailFastOnLocked is set.
+// It returns the records that were set.
 func (p *Provider) SetRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
-	var rls []libdns.Record
+	results := make([]*aliDomainRecord, len(recs))
 	var errs = OpErrors("SetRecords")
-	for _, rec := range recs {
-		ar := alidnsRecord(rec, zone)
-		if ar.RecordID == "" {
-			r0, err := p.queryDomainRecord(ctx, ar.Rr, ar.DomainName, ar.DomainType, ar.DomainValue)
-			if err == nil {
-				ar.RecordID = r0.RecordID
-			}
-			if ar.Rr == r0.Rr && len(ar.RecordID) > 0 {
-				_, err := p.delDomainRecord(ctx, ar)
-				if err != nil {
-					errs.JoinRecord(rec, err)
-					continue
-				}
-				ar.RecordID = ""
-			}
-			if len(ar.RecordID) == 0 {
-				ar.RecordID, err = p.addDomainRecord(ctx, ar)
-				if err != nil {
-					errs.JoinRecord(rec, err)
-				} else {
-					rls = append(rls, ar.DomainRecord())
-				}
-				continue
-			}
+	var plans []*rrSetPlan
+	locked := false
+	for _, set := range groupRRSets(recs, zone) {
+		i, err := set.validate()
+		if err == nil {
+			i, err = p.validateLines(ctx, zone, set)
 		}
-		_, err := p.setDomainRecord(ctx, ar)
 		if err != nil {
-			errs.JoinRecord(rec, err)
+			// the RRset is left alone if any of its records is invalid
+			errs.JoinRecord(recs[i], err)
 			continue
 		}
-		rls = append(rls, ar.DomainRecord())
+		plan, err := p.planRRSet(ctx, zone, set)
+		if err != nil {
+			for _, i := range set.indexes {
+				errs.JoinRecord(recs[i], err)
+			}
+			continue
+		}
+		for _, rec := range plan.locked {
+			errs.JoinError(&LockedRecordError{Record: rec.libdnsRecord()})
+			locked = true
+		}
+		plans = append(plans, plan)
+	}
+	if locked && p.FailFastOnLocked {
+		return nil, errs.Error()
+	}
+	for _, plan := range plans {
+		err := p.applyRRSet(ctx, plan, results)
+		if err != nil {
+			for _, i := range plan.set.indexes {
+				errs.JoinRecord(recs[i], err)
+			}
+		}
+	}
+	var rls []libdns.Record
+	for _, rec := range results {
+		if rec != nil {
+			rls = append(rls, rec.libdnsRecord())
+		}
 	}
 	return rls, errs.Error()
 }
 
-func (p *Provider) getClient() error {
-	return p.getClientWithZone("")
+// rrSetPlan is the changes of the RRset which are planned before any of them is applied
+type rrSetPlan struct {
+	set      *rrSet
+	existing []aliDomainRecord
+	changes  rrSetChanges
+	// locked records which the changes would update or delete, they are left alone
+	locked []aliDomainRecord
 }
 
-func (p *Provider) getClientWithZone(zone string) error {
-	var err error
-	if len(zone) == 0 {
-		p.client, err = getClient(&p.CredentialInfo)
-	} else {
-		p.client, err = getClient(&p.CredentialInfo, zone)
+func (p *Provider) planRRSet(ctx context.Context, zone string, set *rrSet) (*rrSetPlan, error) {
+	cl, err := p.getClientWithZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	for i := range set.records {
+		set.records[i].TTL = cl.ttlOf(set.records[i].TTL)
 	}
+	live, err := p.querySubDomainRecords(ctx, zone, set.Rr, set.DomainType)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	plan := &rrSetPlan{set: set}
+	for _, rec := range live {
+		if lineOf(rec.Line) == set.Line {
+			plan.existing = append(plan.existing, rec)
+		}
+	}
+	plan.changes = diffRRSet(plan.existing, set.records)
+	byID := map[string]aliDomainRecord{}
+	for _, rec := range plan.existing {
+		byID[rec.RecordID] = rec
+	}
+	for di, rec := range plan.changes.unchanged {
+		if p.EnableDisabledRecords && rec.Locked && rec.disabled() {
+			plan.locked = append(plan.locked, rec)
+			delete(plan.changes.unchanged, di)
+		}
+	}
+	for di, rec := range plan.changes.updates {
+		if prev := byID[rec.RecordID]; prev.Locked {
+			plan.locked = append(plan.locked, prev)
+			delete(plan.changes.updates, di)
+		}
+	}
+	deletes := plan.changes.deletes[:0]
+	for _, rec := range plan.changes.deletes {
+		if rec.Locked {
+			plan.locked = append(plan.locked, rec)
+			continue
+		}
+		deletes = append(deletes, rec)
+	}
+	plan.changes.deletes = deletes
+	return plan, nil
+}
+
+func (p *Provider) applyRRSet(ctx context.Context, plan *rrSetPlan, results []*aliDomainRecord) error {
+	set, changes := plan.set, plan.changes
+	var err error
+	for di := range set.records {
+		live, ok := changes.unchanged[di]
+		if !ok {
+			continue
+		}
+		rec := set.records[di]
+		rec.RecordID = live.RecordID
+		live, err = p.setRecordMeta(ctx, rec, live)
+		if err != nil {
+			return err
+		}
+		results[set.indexes[di]] = &live
+	}
+	byID := map[string]aliDomainRecord{}
+	for _, rec := range plan.existing {
+		byID[rec.RecordID] = rec
+	}
+	for di := range set.records {
+		rec, ok := changes.updates[di]
+		if !ok {
+			continue
+		}
+		_, err = p.setDomainRecord(ctx, rec)
+		if err != nil {
+			return err
+		}
+		updated := rec
+		prev := byID[rec.RecordID]
+		updated.Status, updated.Locked, updated.CreateTime = prev.Status, prev.Locked, prev.CreateTime
+		updated.Remark, updated.Weight = prev.Remark, prev.Weight
+		updated, err = p.setRecordMeta(ctx, rec, updated)
+		if err != nil {
+			return err
+		}
+		results[set.indexes[di]] = &updated
+	}
+	for _, di := range changes.creates {
+		rec := set.records[di]
+		rec.RecordID, err = p.addDomainRecord(ctx, rec)
+		if err != nil {
+			return err
+		}
+		added := rec
+		added.Remark, added.Weight = "", 0
+		added, err = p.setRecordMeta(ctx, rec, added)
+		if err != nil {
+			return err
+		}
+		results[set.indexes[di]] = &added
+	}
+	for _, rec := range changes.deletes {
+		_, err = p.delDomainRecord(ctx, rec)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// ListZones lists all the zones which the credential can access.
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	return p.ListZonesWithFilter(ctx, ZoneFilter{})
+}
+
+// ListZonesWithFilter lists the zones which the credential can access and match the filter.
+func (p *Provider) ListZonesWithFilter(ctx context.Context, filter ZoneFilter) ([]libdns.Zone, error) {
+	var zones []libdns.Zone
+	infos, err := p.queryDomainList(ctx, filter)
+	if err != nil {
+		return nil, OpError("ListZones", err)
+	}
+	for _, info := range infos {
+		zones = append(zones, info.Zone())
+	}
+	return zones, nil
+}
+
+func (p *Provider) getClient(ctx context.Context) (*aliClient, error) {
+	cred, err := p.credential(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cl, err := getClient(&cred)
+	if err != nil {
+		return cl, err
+	}
+	if len(p.Endpoint) > 0 {
+		cl.schema.APIHost, err = apiEndpoint(p.Endpoint)
+		if err != nil {
+			return cl, err
+		}
+	}
+	cl.retry = p.RetryPolicy
+	cl.httpClient = p.HTTPClient
+	return cl, nil
+}
+
+// credential returns CredentialInfo of the Provider if it is set, otherwise
+// the credential retrieved from CredentialProvider or DefaultCredentialChain.
+func (p *Provider) credential(ctx context.Context) (CredentialInfo, error) {
+	p.credMutex.RLock()
+	static := p.CredentialInfo
+	p.credMutex.RUnlock()
+	if len(static.AccessKeyID) > 0 || len(static.AccessKeySecret) > 0 {
+		return static, nil
+	}
+	src := p.CredentialProvider
+	if src == nil {
+		src = p.chain.get(p.Profile)
+	}
+	cred, err := src.Retrieve(ctx)
+	if err != nil {
+		return CredentialInfo{}, err
+	}
+	if len(cred.RegionID) == 0 {
+		cred.RegionID = static.RegionID
+	}
+	return cred, nil
+}
+
+// SetCredential replaces CredentialInfo of the Provider, it is safe to call while
+// the Provider is in use. Requests in flight finish with the former credential,
+// and the following requests are signed with the new one.
+func (p *Provider) SetCredential(cred CredentialInfo) {
+	p.credMutex.Lock()
+	defer p.credMutex.Unlock()
+	p.CredentialInfo = cred
+}
+
+func (p *Provider) getClientWithZone(ctx context.Context, zone string) (*aliClient, error) {
+	cl, err := p.getClient(ctx)
+	if err != nil || len(zone) == 0 {
+		return cl, err
+	}
+	info, err := p.zoneInfo(ctx, zone)
+	if err != nil {
+		return cl, err
+	}
+	return cl.withZone(info), nil
+}
+
 func (p *Provider) addDomainRecord(ctx context.Context, rc aliDomainRecord) (recID string, err error) {
-	err = p.getClientWithZone(rc.DomainName)
+	cl, err := p.getClientWithZone(ctx, rc.DomainName)
 	if err != nil {
 		return "", err
 	}
-	if !p.client.IsEntprienseEdition() {
-		rc.TTL = min(rc.TTL, 600)
+	rc.TTL = cl.ttlOf(rc.TTL)
+	recID, err = cl.addDomainRecord(ctx, rc)
+	if cl.uncertain && IsDuplicate(err) {
+		// a former attempt may have added the record before failing,
+		// otherwise the record already existed before the call
+		live, qerr := p.querySubDomainRecords(ctx, rc.DomainName, rc.Rr, rc.DomainType)
+		if qerr != nil {
+			return "", err
+		}
+		for _, r0 := range live {
+			if r0.sameData(rc) && lineOf(r0.Line) == lineOf(rc.Line) {
+				return r0.RecordID, nil
+			}
+		}
 	}
-	return p.client.addDomainRecord(ctx, rc)
+	return recID, err
 }
 
 func (p *Provider) delDomainRecord(ctx context.Context, rc aliDomainRecord) (recID string, err error) {
-	err = p.getClientWithZone(rc.DomainName)
+	cl, err := p.getClient(ctx)
 	if err != nil {
 		return "", err
 	}
-	if !p.client.IsEntprienseEdition() {
-		rc.TTL = min(rc.TTL, 600)
+	return cl.delDomainRecord(ctx, rc)
+}
+
+// setRecordMeta sets the remark and weight of the record, if they are specified and differ from the live ones,
+// and enables the record if it is disabled and EnableDisabledRecords is set.
+// It returns the record with the metadata which is live now.
+func (p *Provider) setRecordMeta(ctx context.Context, rc, live aliDomainRecord) (aliDomainRecord, error) {
+	result := live
+	if p.EnableDisabledRecords && live.disabled() {
+		err := p.setDomainRecordStatus(ctx, rc.RecordID, statusEnable)
+		if err != nil {
+			return result, err
+		}
+		result.Status = strings.ToUpper(statusEnable)
+	}
+	if len(rc.Remark) > 0 && rc.Remark != live.Remark {
+		cl, err := p.getClient(ctx)
+		if err != nil {
+			return result, err
+		}
+		err = cl.setDomainRecordRemark(ctx, rc.RecordID, rc.Remark)
+		if err != nil {
+			return result, err
+		}
+		result.Remark = rc.Remark
+	}
+	if rc.Weight > 0 && rc.Weight != live.Weight {
+		cl, err := p.getClient(ctx)
+		if err != nil {
+			return result, err
+		}
+		err = cl.setDNSSLBWeight(ctx, rc.RecordID, rc.Weight)
+		if err != nil {
+			return result, err
+		}
+		result.Weight = rc.Weight
 	}
-	return p.client.delDomainRecord(ctx, rc)
+	return result, nil
 }
 
 func (p *Provider) setDomainRecord(ctx context.Context, rc aliDomainRecord) (recID string, err error) {
-	err = p.getClientWithZone(rc.DomainName)
+	cl, err := p.getClientWithZone(ctx, rc.DomainName)
 	if err != nil {
 		return "", err
 	}
-	if !p.client.IsEntprienseEdition() {
-		rc.TTL = min(rc.TTL, 600)
-	}
-	return p.client.setDomainRecord(ctx, rc)
+	rc.TTL = cl.ttlOf(rc.TTL)
+	return cl.setDomainRecord(ctx, rc)
 }
 
 func (p *Provider) getDomainRecord(ctx context.Context, recID string) (aliDomainRecord, error) {
-	p.getClient()
-	return p.client.getDomainRecord(ctx, recID)
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return aliDomainRecord{}, err
+	}
+	return cl.getDomainRecord(ctx, recID)
 }
 
 func (p *Provider) queryDomainRecords(ctx context.Context, name string) ([]aliDomainRecord, error) {
-	p.getClient()
-	return p.client.queryDomainRecords(ctx, name)
+	pageSize := p.recordPageSize()
+	rs, err := p.queryDomainRecordsPage(ctx, name, 1, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	result := rs.DomainRecords.Record
+	pageCount := (rs.TotalCount + pageSize - 1) / pageSize
+	if pageCount <= 1 || len(result) == 0 {
+		return result, nil
+	}
+	if p.RecordPageConcurrency <= 1 {
+		for pageNumber := 2; pageNumber <= pageCount; pageNumber++ {
+			rs, err = p.queryDomainRecordsPage(ctx, name, pageNumber, pageSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(rs.DomainRecords.Record) == 0 {
+				break
+			}
+			result = append(result, rs.DomainRecords.Record...)
+		}
+		return result, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pages := make([][]aliDomainRecord, pageCount+1)
+	var firstErr error
+	var once sync.Once
+	sem := make(chan struct{}, p.RecordPageConcurrency)
+	var wg sync.WaitGroup
+	for pageNumber := 2; pageNumber <= pageCount; pageNumber++ {
+		wg.Add(1)
+		go func(pageNumber int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			rs, err := p.queryDomainRecordsPage(ctx, name, pageNumber, pageSize)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			pages[pageNumber] = rs.DomainRecords.Record
+		}(pageNumber)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	for pageNumber := 2; pageNumber <= pageCount; pageNumber++ {
+		result = append(result, pages[pageNumber]...)
+	}
+	return result, nil
+}
+
+func (p *Provider) queryDomainRecordsPage(ctx context.Context, name string, pageNumber, pageSize int) (aliDomainResult, error) {
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return aliDomainResult{}, err
+	}
+	status := ""
+	if p.ExcludeDisabledRecords {
+		status = statusEnable
+	}
+	return cl.queryDomainRecords(ctx, name, status, pageNumber, pageSize)
+}
+
+func (p *Provider) recordPageSize() int {
+	if p.RecordPageSize <= 0 || p.RecordPageSize > maxPageSizeOfRecords {
+		return maxPageSizeOfRecords
+	}
+	return p.RecordPageSize
+}
+
+func (p *Provider) querySubDomainRecords(ctx context.Context, zone, rr string, recType string) ([]aliDomainRecord, error) {
+	var result []aliDomainRecord
+	recType = strings.ToUpper(recType)
+	for pageNumber := 1; ; pageNumber++ {
+		cl, err := p.getClientWithZone(ctx, zone)
+		if err != nil {
+			return nil, err
+		}
+		subDomain := libdns.AbsoluteName(rr, cl.domainName(zone))
+		rs, err := cl.querySubDomainRecords(ctx, subDomain, zone, recType, pageNumber, maxPageSizeOfRecords)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range rs.DomainRecords.Record {
+			if strings.EqualFold(rec.Rr, rr) && (recType == "" || rec.DomainType == recType) {
+				result = append(result, rec)
+			}
+		}
+		if len(rs.DomainRecords.Record) == 0 || pageNumber*maxPageSizeOfRecords >= rs.TotalCount {
+			return result, nil
+		}
+	}
 }
 
 func (p *Provider) queryDomainRecord(ctx context.Context, rr, name string, recType string, recVal ...string) (aliDomainRecord, error) {
-	p.getClient()
-	return p.client.queryDomainRecord(ctx, rr, name, recType, recVal...)
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return aliDomainRecord{}, err
+	}
+	return cl.queryDomainRecord(ctx, rr, name, recType, recVal...)
+}
+
+func (p *Provider) queryDomainList(ctx context.Context, filter ZoneFilter) ([]aliDomainInfo, error) {
+	var result []aliDomainInfo
+	for pageNumber := 1; ; pageNumber++ {
+		cl, err := p.getClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := cl.queryDomainList(ctx, filter, pageNumber, maxPageSizeOfDomains)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rs.Domains.Domain...)
+		if len(rs.Domains.Domain) == 0 || len(result) >= rs.TotalCount {
+			return result, nil
+		}
+	}
 }
 
 // Interface guards
@@ -178,4 +723,5 @@ var (
 	_ libdns.RecordAppender = (*Provider)(nil)
 	_ libdns.RecordSetter   = (*Provider)(nil)
 	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
 )
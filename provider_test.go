@@ -0,0 +1,202 @@
+package alidns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+// fakeProvider returns a Provider which sends requests to an alidnstest.Server hosting example.com
+func fakeProvider(t *testing.T) (*Provider, *alidnstest.Server) {
+	srv := alidnstest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddZone(alidnstest.Zone{DomainName: "example.com"})
+	return &Provider{
+		CredentialInfo: CredentialInfo{
+			AccessKeyID:     srv.AccessKeyID,
+			AccessKeySecret: srv.AccessKeySecret,
+		},
+		Endpoint:   srv.URL,
+		HTTPClient: srv.Client(),
+	}, srv
+}
+
+func TestProviderConcurrentUse(t *testing.T) {
+	p, _ := fakeProvider(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 16; i++ {
+		wg.Add(4)
+		go func(i int) {
+			defer wg.Done()
+			recs, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+				libdns.RR{Name: fmt.Sprintf("sub%d", i), Type: "A", Data: "1.1.1.1"},
+			})
+			if err == nil && len(recs) != 1 {
+				err = fmt.Errorf("excepted 1 record, got %d", len(recs))
+			}
+			errs <- err
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := p.GetRecords(context.TODO(), "example.com.")
+			errs <- err
+		}()
+		go func(i int) {
+			defer wg.Done()
+			_, err := p.SetRecords(context.TODO(), "example.com.", []libdns.Record{
+				libdns.RR{Name: fmt.Sprintf("set%d", i), Type: "TXT", Data: "hello"},
+			})
+			errs <- err
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := p.ListZones(context.TODO())
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestProviderEndpoint(t *testing.T) {
+	var hosts sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts.Store(r.Host, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"TotalCount":1,"Domains":{"Domain":[{"DomainName":"example.com"}]}}`)
+	}))
+	defer srv.Close()
+
+	p, _ := fakeProvider(t)
+	p.Endpoint = srv.URL
+	p.HTTPClient = srv.Client()
+	zones, err := p.ListZones(context.TODO())
+	if err != nil || len(zones) != 1 || zones[0].Name != "example.com." {
+		t.Fatal("excepted zones from the endpoint, got:", zones, err)
+	}
+	if path, ok := hosts.Load(strings.TrimPrefix(srv.URL, "http://")); !ok || path != "/" {
+		t.Error("excepted the request sent to the endpoint, got:", path)
+	}
+}
+
+func TestProviderSetCredential(t *testing.T) {
+	p, srv := fakeProvider(t)
+	// a temporary credential of STS is accepted by the server as well as its own key
+	sts := &AssumeRoleCredentials{
+		Credential: p.CredentialInfo,
+		RoleArn:    testRoleArn,
+		Endpoint:   srv.URL,
+		HTTPClient: srv.Client(),
+	}
+	rotated, err := sts.Retrieve(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := []CredentialInfo{p.CredentialInfo, rotated}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 32; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			p.SetCredential(creds[i%2])
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := p.GetRecords(context.TODO(), "example.com."); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error("excepted requests to pass while rotating credentials, got:", err)
+	}
+
+	p.SetCredential(CredentialInfo{AccessKeyID: srv.AccessKeyID, AccessKeySecret: "revoked"})
+	if _, err = p.GetRecords(context.TODO(), "example.com."); !IsAuth(err) {
+		t.Error("excepted requests to be signed with the new credential, got:", err)
+	}
+}
+
+func TestDeleteSubDomainRecords(t *testing.T) {
+	cases := []struct {
+		memo     string
+		recType  string
+		locked   bool
+		failFast bool
+		deleted  int
+		left     int
+		requests int
+	}{
+		{memo: "all types and lines", deleted: 3, left: 1, requests: 1},
+		{memo: "type filter", recType: "A", deleted: 2, left: 2, requests: 1},
+		{memo: "type filter in lower case", recType: "txt", deleted: 1, left: 3, requests: 1},
+		{memo: "skip locked records", locked: true, deleted: 2, left: 2, requests: 0},
+		{memo: "fail fast on locked records", locked: true, failFast: true, deleted: 0, left: 4, requests: 0},
+	}
+	for _, c := range cases {
+		p, srv := fakeProvider(t)
+		p.FailFastOnLocked = c.failFast
+		for _, rec := range []alidnstest.Record{
+			{DomainName: "example.com", RR: "old", Type: "A", Value: "192.0.2.1"},
+			{DomainName: "example.com", RR: "old", Type: "A", Value: "192.0.2.1", Line: "telecom"},
+			{DomainName: "example.com", RR: "old", Type: "TXT", Value: "decommissioned", Locked: c.locked},
+			{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.2"},
+		} {
+			if _, err := srv.AddRecord(rec); err != nil {
+				t.Fatal(err)
+			}
+		}
+		recs, err := p.DeleteSubDomainRecords(context.TODO(), "example.com.", "old", c.recType)
+		if (err != nil) != c.locked || (c.locked && !IsLocked(err)) {
+			t.Error("case", c.memo, "got unexcepted error:", err)
+			continue
+		}
+		live := srv.Records("example.com")
+		ok := len(recs) == c.deleted && len(live) == c.left &&
+			srv.Requests("DeleteSubDomainRecords") == c.requests
+		if !ok {
+			t.Error("case", c.memo, "got:", recs, live)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+
+	p, srv := fakeProvider(t)
+	recs, err := p.DeleteSubDomainRecords(context.TODO(), "example.com.", "nonexistent", "")
+	if err != nil || len(recs) != 0 || srv.Requests("DeleteSubDomainRecords") != 0 {
+		t.Error("excepted nothing deleted without any request, got:", recs, err)
+	}
+}
+
+func TestDeleteRecordsTypeCase(t *testing.T) {
+	p, srv := fakeProvider(t)
+	if _, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "old", Type: "TXT", Value: "decommissioned"}); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := p.DeleteRecords(context.TODO(), "example.com.", []libdns.Record{libdns.RR{Name: "old", Type: "txt"}})
+	if err != nil || len(recs) != 1 {
+		t.Error("excepted 1 record deleted by the type in lower case, got:", recs, err)
+	}
+	if live := srv.Records("example.com"); len(live) != 0 {
+		t.Error("excepted no records left, got:", live)
+	}
+}
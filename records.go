@@ -0,0 +1,346 @@
+package alidns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/libdns/libdns"
+)
+
+// ProviderData is set in the ProviderData field of the typed records returned by the Provider.
+// It is also accepted in the ProviderData field of input records, where Line, Weight and Remark
+// are set when the records are appended or set, and the others are ignored.
+type ProviderData struct {
+	// ID of the record in Alidns, which identifies the record when it is deleted or set
+	RecordID string
+	// Optional resolution line of the record, the default is "default"
+	Line string
+	// Status of the record, ENABLE or DISABLE
+	Status string
+	// Whether the record is locked against changes
+	Locked bool
+	// Optional weight of the record between 1 and 100, which takes effect if DNS SLB is enabled for its subdomain
+	Weight int
+	// Optional remark of the record up to 50 characters, e.g. the owner or ticket of the record
+	Remark string
+	// Time when the record was created
+	CreateTime time.Time
+	// Time when the record was updated last
+	UpdateTime time.Time
+}
+
+// providerData returns the metadata of the record
+func (r aliDomainRecord) providerData() ProviderData {
+	result := ProviderData{
+		RecordID: r.RecordID,
+		Line:     r.Line,
+		Status:   r.Status,
+		Locked:   r.Locked,
+		Weight:   r.Weight,
+		Remark:   r.Remark,
+	}
+	if r.CreateTime > 0 {
+		result.CreateTime = time.UnixMilli(r.CreateTime)
+	}
+	if r.UpdateTime > 0 {
+		result.UpdateTime = time.UnixMilli(r.UpdateTime)
+	}
+	return result
+}
+
+// libdnsRecord converts the record to the typed libdns record of its type, e.g. libdns.MX,
+// or to DomainRecord if the type is not supported by libdns or the value is malformed.
+func (r aliDomainRecord) libdnsRecord() libdns.Record {
+	rec, err := r.DomainRecord().RR().Parse()
+	if _, unknown := rec.(libdns.RR); unknown || err != nil {
+		return r.DomainRecord()
+	}
+	return withProviderData(rec, r.providerData())
+}
+
+// withProviderData sets the ProviderData of the typed record, other records are returned as is
+func withProviderData(rec libdns.Record, data ProviderData) libdns.Record {
+	switch r := rec.(type) {
+	case libdns.Address:
+		r.ProviderData = data
+		return r
+	case libdns.CAA:
+		r.ProviderData = data
+		return r
+	case libdns.CNAME:
+		r.ProviderData = data
+		return r
+	case libdns.MX:
+		r.ProviderData = data
+		return r
+	case libdns.NS:
+		r.ProviderData = data
+		return r
+	case libdns.SRV:
+		r.ProviderData = data
+		return r
+	case libdns.ServiceBinding:
+		r.ProviderData = data
+		return r
+	case libdns.TXT:
+		r.ProviderData = data
+		return r
+	}
+	return rec
+}
+
+// providerDataOf returns the ProviderData of the record returned by the Provider
+func providerDataOf(rec libdns.Record) (ProviderData, bool) {
+	var data interface{}
+	switch r := rec.(type) {
+	case DomainRecord:
+		return ProviderData{RecordID: r.ID}, len(r.ID) > 0
+	case libdns.Address:
+		data = r.ProviderData
+	case libdns.CAA:
+		data = r.ProviderData
+	case libdns.CNAME:
+		data = r.ProviderData
+	case libdns.MX:
+		data = r.ProviderData
+	case libdns.NS:
+		data = r.ProviderData
+	case libdns.SRV:
+		data = r.ProviderData
+	case libdns.ServiceBinding:
+		data = r.ProviderData
+	case libdns.TXT:
+		data = r.ProviderData
+	}
+	switch d := data.(type) {
+	case ProviderData:
+		return d, true
+	case *ProviderData:
+		if d != nil {
+			return *d, true
+		}
+	}
+	return ProviderData{}, false
+}
+
+// splitPriority splits the leading priority from the data of MX records,
+// which Alidns accepts in the separate parameter Priority.
+func splitPriority(data string) (ttl_t, string, bool) {
+	fields := strings.Fields(data)
+	if len(fields) != 2 {
+		return 0, data, false
+	}
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return 0, data, false
+	}
+	return ttl_t(priority), fields[1], true
+}
+
+// svcParamKeys are the SvcParamKeys registered by RFC 9460 in the order of their numbers
+var svcParamKeys = []string{"mandatory", "alpn", "no-default-alpn", "port", "ipv4hint", "ech", "ipv6hint"}
+
+// svcParamOrder returns the number of the SvcParamKey, which SvcParams are sorted by
+func svcParamOrder(key string) int {
+	for i, k := range svcParamKeys {
+		if k == key {
+			return i
+		}
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(key, "key")); err == nil && strings.HasPrefix(key, "key") {
+		return n
+	}
+	return 1 << 16
+}
+
+// serviceBindingValue formats the record in the presentation format "priority target params"
+// which Alidns expects as the value of HTTPS and SVCB records. SvcParams are sorted by their keys,
+// and omitted in AliasMode (priority 0).
+func serviceBindingValue(svcb libdns.ServiceBinding) string {
+	target := svcb.Target
+	if target == "" {
+		target = "."
+	}
+	result := fmt.Sprintf("%d %s", svcb.Priority, target)
+	if svcb.Priority == 0 || len(svcb.Params) == 0 {
+		return result
+	}
+	keys := make([]string, 0, len(svcb.Params))
+	for k := range svcb.Params {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		oi, oj := svcParamOrder(keys[i]), svcParamOrder(keys[j])
+		if oi != oj {
+			return oi < oj
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		result += " " + k
+		vals := svcb.Params[k]
+		if len(vals) == 0 {
+			continue
+		}
+		escaped := make([]string, len(vals))
+		for i, v := range vals {
+			v = strings.ReplaceAll(v, `\`, `\\`)
+			v = strings.ReplaceAll(v, `"`, `\"`)
+			escaped[i] = strings.ReplaceAll(v, ",", `\,`)
+		}
+		value := strings.Join(escaped, ",")
+		if strings.ContainsAny(value, " \t;()") || value == "" {
+			value = `"` + value + `"`
+		}
+		result += "=" + value
+	}
+	return result
+}
+
+// caaTags are the property tags of CAA records which Alidns accepts
+var caaTags = []string{"issue", "issuewild", "iodef"}
+
+// caaValue validates the value of CAA records in the form of `flags tag "value"`,
+// and returns it with the value quoted if it was not.
+func caaValue(data string) (string, error) {
+	fields := strings.SplitN(strings.TrimSpace(data), " ", 3)
+	if len(fields) != 3 {
+		return data, fmt.Errorf(`%w: CAA value %q is not in the form of 'flags tag "value"'`, ErrInvalidRecord, data)
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil || (flags != 0 && flags != 128) {
+		return data, fmt.Errorf("%w: flags %s of CAA record should be 0 or 128", ErrInvalidRecord, fields[0])
+	}
+	tag := strings.ToLower(fields[1])
+	validTag := false
+	for _, t := range caaTags {
+		validTag = validTag || t == tag
+	}
+	if !validTag {
+		return data, fmt.Errorf("%w: tag %s of CAA record should be one of %s", ErrInvalidRecord, fields[1], strings.Join(caaTags, ", "))
+	}
+	value := strings.TrimSpace(fields[2])
+	if strings.HasPrefix(value, `"`) {
+		value, err = strconv.Unquote(value)
+		if err != nil {
+			return data, fmt.Errorf("%w: value %s of CAA record is not quoted properly", ErrInvalidRecord, fields[2])
+		}
+	} else if strings.ContainsAny(value, "\" \t") {
+		return data, fmt.Errorf("%w: value %s of CAA record should be quoted", ErrInvalidRecord, fields[2])
+	}
+	switch tag {
+	case "iodef":
+		if !strings.HasPrefix(value, "mailto:") && !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+			return data, fmt.Errorf("%w: value %q of CAA iodef record should be a mailto, http or https URL", ErrInvalidRecord, value)
+		}
+	default:
+		issuer := strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+		if strings.ContainsAny(issuer, " \t") {
+			return data, fmt.Errorf("%w: issuer %q of CAA record is not a domain name", ErrInvalidRecord, issuer)
+		}
+	}
+	return fmt.Sprintf("%d %s %s", flags, tag, strconv.Quote(value)), nil
+}
+
+// srvValue validates the name and value of SRV records in the form of "priority weight port target",
+// and returns the value with the fields separated by single spaces.
+func srvValue(rr, data string) (string, error) {
+	labels := strings.Split(rr, ".")
+	if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") ||
+		len(labels[0]) < 2 || len(labels[1]) < 2 {
+		return data, fmt.Errorf("%w: name %s of SRV record is not in the form of _service._proto.name", ErrInvalidRecord, rr)
+	}
+	fields := strings.Fields(data)
+	if len(fields) != 4 {
+		return data, fmt.Errorf("%w: SRV value %q is not in the form of 'priority weight port target'", ErrInvalidRecord, data)
+	}
+	for i, name := range []string{"priority", "weight", "port"} {
+		if _, err := strconv.ParseUint(fields[i], 10, 16); err != nil {
+			return data, fmt.Errorf("%w: %s %s of SRV record should be between 0 and 65535", ErrInvalidRecord, name, fields[i])
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// maxRemarkLength is the maximum number of characters of remarks which Alidns accepts
+const maxRemarkLength = 50
+
+// validateRemark checks the length of the remark
+func validateRemark(remark string) error {
+	if n := utf8.RuneCountInString(remark); n > maxRemarkLength {
+		return fmt.Errorf("%w: remark has %d characters, the maximum is %d", ErrInvalidRecord, n, maxRemarkLength)
+	}
+	return nil
+}
+
+// minimum and maximum weights of records which Alidns accepts
+const (
+	minWeight = 1
+	maxWeight = 100
+)
+
+// validateWeight checks the range of the weight
+func validateWeight(weight int) error {
+	if weight < minWeight || weight > maxWeight {
+		return fmt.Errorf("%w: weight %d is out of the range from %d to %d", ErrInvalidRecord, weight, minWeight, maxWeight)
+	}
+	return nil
+}
+
+// minimum and maximum priorities of MX records which Alidns accepts
+const (
+	minMXPriority = 1
+	maxMXPriority = 50
+)
+
+// validatePriority checks the range of the priority of MX records
+func validatePriority(priority ttl_t) error {
+	if priority < minMXPriority || priority > maxMXPriority {
+		return fmt.Errorf("%w: MX priority %d is out of the range from %d to %d", ErrInvalidRecord, priority, minMXPriority, maxMXPriority)
+	}
+	return nil
+}
+
+// validateRecord checks the record locally before it is sent to Alidns,
+// and normalizes the values of SRV and CAA records.
+// Priorities of MX records are checked as well, since Alidns caps them at 50 silently.
+func validateRecord(r *aliDomainRecord) error {
+	err := validateRemark(r.Remark)
+	if err == nil && r.Weight != 0 {
+		err = validateWeight(r.Weight)
+	}
+	if err != nil {
+		return err
+	}
+	switch r.DomainType {
+	case "SRV":
+		r.DomainValue, err = srvValue(r.Rr, r.DomainValue)
+	case "CAA":
+		r.DomainValue, err = caaValue(r.DomainValue)
+	case "MX":
+		err = validatePriority(r.Priority)
+	}
+	return err
+}
+
+// SetRecordRemark sets the remark of the record by its ID, an empty remark clears it.
+func (p *Provider) SetRecordRemark(ctx context.Context, recordID string, remark string) error {
+	err := validateRemark(remark)
+	if err != nil {
+		return OpError("SetRecordRemark", err)
+	}
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return OpError("SetRecordRemark", err)
+	}
+	err = cl.setDomainRecordRemark(ctx, recordID, remark)
+	if err != nil {
+		return OpError("SetRecordRemark", err)
+	}
+	return nil
+}
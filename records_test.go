@@ -0,0 +1,419 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	p, srv := fakeProvider(t)
+	ttl := 600 * time.Second
+	type testCase struct {
+		memo     string
+		record   libdns.Record
+		value    string
+		priority uint32
+	}
+
+	cases := []testCase{
+		{
+			memo:   "A record",
+			record: libdns.Address{Name: "www", TTL: ttl, IP: netip.MustParseAddr("192.0.2.1")},
+			value:  "192.0.2.1",
+		},
+		{
+			memo:   "AAAA record",
+			record: libdns.Address{Name: "www", TTL: ttl, IP: netip.MustParseAddr("2001:db8::1")},
+			value:  "2001:db8::1",
+		},
+		{
+			memo:   "CNAME record",
+			record: libdns.CNAME{Name: "alias", TTL: ttl, Target: "www.example.com."},
+			value:  "www.example.com.",
+		},
+		{
+			memo:   "TXT record",
+			record: libdns.TXT{Name: "_acme-challenge", TTL: ttl, Text: "token value"},
+			value:  "token value",
+		},
+		{
+			memo:     "MX record",
+			record:   libdns.MX{Name: "@", TTL: ttl, Preference: 10, Target: "mail.example.com."},
+			value:    "mail.example.com.",
+			priority: 10,
+		},
+		{
+			memo:   "NS record",
+			record: libdns.NS{Name: "sub", TTL: ttl, Target: "ns1.example.net."},
+			value:  "ns1.example.net.",
+		},
+		{
+			memo: "SRV record",
+			record: libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", TTL: ttl,
+				Priority: 1, Weight: 5, Port: 5060, Target: "sip.example.com."},
+			value: "1 5 5060 sip.example.com.",
+		},
+		{
+			memo:   "CAA record",
+			record: libdns.CAA{Name: "@", TTL: ttl, Flags: 0, Tag: "issue", Value: "letsencrypt.org"},
+			value:  `0 issue "letsencrypt.org"`,
+		},
+	}
+
+	for _, c := range cases {
+		recs, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{c.record})
+		if err != nil || len(recs) != 1 {
+			t.Log("case", c.memo, "append got:", recs, err)
+			t.Fail()
+			continue
+		}
+		data, ok := providerDataOf(recs[0])
+		if !ok || data.RecordID == "" {
+			t.Log("case", c.memo, "excepted RecordID in ProviderData, got:", recs[0])
+			t.Fail()
+			continue
+		}
+		var live *alidnstest.Record
+		for _, r0 := range srv.Records("example.com") {
+			if r0.RecordID == data.RecordID {
+				live = &r0
+				break
+			}
+		}
+		if live == nil || live.Value != c.value || live.Priority != c.priority {
+			t.Log("case", c.memo, "excepted value", c.value, "and priority", c.priority, "got:", live)
+			t.Fail()
+			continue
+		}
+		if got := withProviderData(recs[0], ProviderData{}); !reflect.DeepEqual(got, withProviderData(c.record, ProviderData{})) {
+			t.Log("case", c.memo, "excepted:", c.record, "got:", got)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+
+	recs, err := p.GetRecords(context.TODO(), "example.com.")
+	if err != nil || len(recs) != len(cases) {
+		t.Fatal("excepted all records, got:", recs, err)
+	}
+	for i, rec := range recs {
+		if got := withProviderData(rec, ProviderData{}); !reflect.DeepEqual(got, withProviderData(cases[i].record, ProviderData{})) {
+			t.Error("excepted GetRecords to return", cases[i].record, "got:", rec)
+		}
+	}
+}
+
+func TestAlidnsRecordMXPriority(t *testing.T) {
+	rec := alidnsRecord(libdns.RR{Name: "@", Type: "MX", Data: "20 mail.example.com."}, "example.com.")
+	if rec.Priority != 20 || rec.DomainValue != "mail.example.com." {
+		t.Error("excepted priority split from the data of MX, got:", rec)
+	}
+	rec = alidnsRecord(DomainRecord{Name: "@", Type: "MX", Value: "mail.example.com.", Priority: 5, ID: "1"})
+	if rec.Priority != 5 || rec.DomainValue != "mail.example.com." || rec.RecordID != "1" {
+		t.Error("excepted priority and ID of DomainRecord, got:", rec)
+	}
+}
+
+func TestLibdnsRecordFallback(t *testing.T) {
+	rec := aliDomainRecord{Rr: "www", DomainType: "REDIRECT_URL", DomainValue: "https://example.com", RecordID: "1"}
+	if _, ok := rec.libdnsRecord().(DomainRecord); !ok {
+		t.Error("excepted DomainRecord for types not supported by libdns")
+	}
+	rec = aliDomainRecord{Rr: "www", DomainType: "A", DomainValue: "not an IP", RecordID: "1"}
+	if _, ok := rec.libdnsRecord().(DomainRecord); !ok {
+		t.Error("excepted DomainRecord for malformed values")
+	}
+}
+
+func TestServiceBindingValue(t *testing.T) {
+	type testCase struct {
+		memo   string
+		record libdns.ServiceBinding
+		value  string
+	}
+
+	cases := []testCase{
+		{
+			memo:   "AliasMode",
+			record: libdns.ServiceBinding{Scheme: "https", Name: "@", Priority: 0, Target: "cdn.example.net."},
+			value:  "0 cdn.example.net.",
+		},
+		{
+			memo: "params are omitted in AliasMode",
+			record: libdns.ServiceBinding{Scheme: "https", Name: "@", Priority: 0, Target: "cdn.example.net.",
+				Params: libdns.SvcParams{"alpn": {"h2"}}},
+			value: "0 cdn.example.net.",
+		},
+		{
+			memo: "params sorted by keys",
+			record: libdns.ServiceBinding{Scheme: "https", Name: "@", Priority: 1, Target: ".",
+				Params: libdns.SvcParams{"ipv4hint": {"192.0.2.1", "192.0.2.2"}, "port": {"8443"}, "alpn": {"h3", "h2"}, "no-default-alpn": {}}},
+			value: "1 . alpn=h3,h2 no-default-alpn port=8443 ipv4hint=192.0.2.1,192.0.2.2",
+		},
+		{
+			memo: "ech in base64",
+			record: libdns.ServiceBinding{Scheme: "https", Name: "@", Priority: 1, Target: ".",
+				Params: libdns.SvcParams{"ech": {"AEX+/gBBAQ=="}}},
+			value: "1 . ech=AEX+/gBBAQ==",
+		},
+		{
+			memo: "escaped and quoted values",
+			record: libdns.ServiceBinding{Scheme: "https", Name: "@", Priority: 2, Target: "svc.example.com.",
+				Params: libdns.SvcParams{"alpn": {"h2", `qu"te`, "sp ace"}}},
+			value: `2 svc.example.com. alpn="h2,qu\"te,sp ace"`,
+		},
+		{
+			memo:   "SVCB record",
+			record: libdns.ServiceBinding{Scheme: "dns", Name: "@", Priority: 1, Target: "dns.example.net.", Params: libdns.SvcParams{"alpn": {"dot"}}},
+			value:  "1 dns.example.net. alpn=dot",
+		},
+	}
+
+	for _, c := range cases {
+		value := serviceBindingValue(c.record)
+		if value != c.value {
+			t.Log("case", c.memo, "excepted:", c.value, "got:", value)
+			t.Fail()
+			continue
+		}
+		ar := alidnsRecord(c.record, "example.com.")
+		ar.TTL = 600
+		parsed, ok := ar.libdnsRecord().(libdns.ServiceBinding)
+		if !ok || parsed.Priority != c.record.Priority || parsed.Target != c.record.Target || parsed.Scheme != c.record.Scheme {
+			t.Log("case", c.memo, "excepted to parse back, got:", ar.libdnsRecord())
+			t.Fail()
+			continue
+		}
+		if c.record.Priority > 0 && !reflect.DeepEqual(parsed.Params, c.record.Params) {
+			t.Log("case", c.memo, "excepted params:", c.record.Params, "got:", parsed.Params)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestServiceBindingRoundTrip(t *testing.T) {
+	p, srv := fakeProvider(t)
+	recs := []libdns.Record{
+		libdns.ServiceBinding{Scheme: "https", Name: "@", TTL: 600 * time.Second, Priority: 0, Target: "cdn.example.net."},
+		libdns.ServiceBinding{Scheme: "https", Name: "www", TTL: 600 * time.Second, Priority: 1, Target: ".",
+			Params: libdns.SvcParams{"alpn": {"h3", "h2"}, "ech": {"AEX+/gBBAQ=="}}},
+		libdns.RR{Name: "api", Type: "HTTPS", TTL: 600 * time.Second, Data: `1 . port=8443 alpn="h2,h3"`},
+	}
+	if _, err := p.AppendRecords(context.TODO(), "example.com.", recs); err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{}
+	for _, r0 := range srv.Records("example.com") {
+		if r0.Priority != 0 {
+			t.Error("excepted no priority parameter of HTTPS records, got:", r0)
+		}
+		values[r0.RR] = r0.Value
+	}
+	if values["api"] != "1 . alpn=h2,h3 port=8443" {
+		t.Error("excepted the RR normalized in presentation format, got:", values["api"])
+	}
+
+	got, err := p.GetRecords(context.TODO(), "example.com.")
+	if err != nil || len(got) != len(recs) {
+		t.Fatal("excepted all records, got:", got, err)
+	}
+	for i, rec := range got {
+		svcb, ok := rec.(libdns.ServiceBinding)
+		if !ok {
+			t.Error("excepted ServiceBinding, got:", rec)
+			continue
+		}
+		excepted, err := recs[i].RR().Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if svcb.Priority != excepted.(libdns.ServiceBinding).Priority || svcb.Target != excepted.(libdns.ServiceBinding).Target {
+			t.Error("excepted:", excepted, "got:", svcb)
+		}
+	}
+
+	// records with the same params in another order are not changed by SetRecords
+	if _, err = p.SetRecords(context.TODO(), "example.com.", []libdns.Record{recs[1]}); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Requests("UpdateDomainRecord") + srv.Requests("AddDomainRecord"); n != len(recs) {
+		t.Error("excepted no changes of SetRecords, got requests:", n)
+	}
+}
+
+func TestValidateRecord(t *testing.T) {
+	type testCase struct {
+		memo   string
+		record libdns.Record
+		value  string
+		fails  bool
+	}
+
+	cases := []testCase{
+		{memo: "SRV record", record: libdns.RR{Name: "_sip._tcp", Type: "SRV", Data: "10  5 5060   sip.example.com."}, value: "10 5 5060 sip.example.com."},
+		{memo: "SRV record of subdomain", record: libdns.SRV{Service: "xmpp", Transport: "tcp", Name: "chat", Priority: 0, Weight: 0, Port: 5222, Target: "."}, value: "0 0 5222 ."},
+		{memo: "SRV name without service", record: libdns.RR{Name: "sip", Type: "SRV", Data: "10 5 5060 sip.example.com."}, fails: true},
+		{memo: "SRV value missing target", record: libdns.RR{Name: "_sip._tcp", Type: "SRV", Data: "10 5 5060"}, fails: true},
+		{memo: "SRV port out of range", record: libdns.RR{Name: "_sip._tcp", Type: "SRV", Data: "10 5 70000 sip.example.com."}, fails: true},
+		{memo: "SRV negative weight", record: libdns.RR{Name: "_sip._tcp", Type: "SRV", Data: "10 -5 5060 sip.example.com."}, fails: true},
+		{memo: "CAA record", record: libdns.CAA{Name: "@", Flags: 0, Tag: "issue", Value: "letsencrypt.org"}, value: `0 issue "letsencrypt.org"`},
+		{memo: "CAA unquoted value", record: libdns.RR{Name: "@", Type: "CAA", Data: "128 issuewild letsencrypt.org"}, value: `128 issuewild "letsencrypt.org"`},
+		{memo: "CAA forbidding issuance", record: libdns.RR{Name: "@", Type: "CAA", Data: `0 issue ";"`}, value: `0 issue ";"`},
+		{memo: "CAA issue with params", record: libdns.CAA{Name: "@", Tag: "issue", Value: "ca.example.net; account=230123"}, value: `0 issue "ca.example.net; account=230123"`},
+		{memo: "CAA iodef", record: libdns.CAA{Name: "@", Tag: "iodef", Value: "mailto:security@example.com"}, value: `0 iodef "mailto:security@example.com"`},
+		{memo: "CAA iodef not an URL", record: libdns.CAA{Name: "@", Tag: "iodef", Value: "security@example.com"}, fails: true},
+		{memo: "CAA unknown tag", record: libdns.CAA{Name: "@", Tag: "issuer", Value: "letsencrypt.org"}, fails: true},
+		{memo: "CAA invalid flags", record: libdns.CAA{Name: "@", Flags: 1, Tag: "issue", Value: "letsencrypt.org"}, fails: true},
+		{memo: "CAA unterminated quote", record: libdns.RR{Name: "@", Type: "CAA", Data: `0 issue "letsencrypt.org`}, fails: true},
+		{memo: "MX record", record: libdns.MX{Name: "@", Preference: 50, Target: "mail.example.com."}, value: "mail.example.com."},
+		{memo: "MX priority too large", record: libdns.MX{Name: "@", Preference: 100, Target: "mail.example.com."}, fails: true},
+		{memo: "MX priority zero", record: libdns.MX{Name: "@", Preference: 0, Target: "mail.example.com."}, fails: true},
+		{memo: "CAA missing value", record: libdns.RR{Name: "@", Type: "CAA", Data: "0 issue"}, fails: true},
+	}
+
+	for _, c := range cases {
+		ar := alidnsRecord(c.record, "example.com.")
+		err := validateRecord(&ar)
+		ok := (err != nil) == c.fails && errors.Is(err, ErrInvalidRecord) == c.fails
+		if !c.fails {
+			ok = ok && ar.DomainValue == c.value
+		}
+		if !ok {
+			t.Log("case", c.memo, "got:", ar.DomainValue, err)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestInvalidRecordsAreNotSent(t *testing.T) {
+	p, srv := fakeProvider(t)
+	invalid := libdns.RR{Name: "_sip._tcp", Type: "SRV", Data: "10 5 70000 sip.example.com."}
+	valid := libdns.RR{Name: "_sip._tcp", Type: "SRV", Data: "10 5 5060 sip.example.com."}
+
+	recs, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{invalid, valid})
+	if !errors.Is(err, ErrInvalidRecord) || len(recs) != 1 {
+		t.Error("excepted the valid record appended and ErrInvalidRecord, got:", recs, err)
+	}
+	if n := srv.Requests("AddDomainRecord"); n != 1 {
+		t.Error("excepted 1 AddDomainRecord request, got:", n)
+	}
+
+	_, err = p.SetRecords(context.TODO(), "example.com.", []libdns.Record{
+		libdns.CAA{Name: "@", Tag: "issuer", Value: "letsencrypt.org"},
+		libdns.CAA{Name: "@", Tag: "issue", Value: "letsencrypt.org"},
+	})
+	var recErr *RecordError
+	if !errors.Is(err, ErrInvalidRecord) || !errors.As(err, &recErr) || recErr.Record.RR().Data != `0 issuer "letsencrypt.org"` {
+		t.Error("excepted ErrInvalidRecord of the CAA record, got:", err)
+	}
+	if n := srv.Requests("DescribeSubDomainRecords"); n != 0 {
+		t.Error("excepted no requests for the invalid RRset, got:", n)
+	}
+}
+
+func TestProviderData(t *testing.T) {
+	p, srv := fakeProvider(t)
+	in := libdns.Address{Name: "www", TTL: 600 * time.Second, IP: netip.MustParseAddr("192.0.2.1"),
+		ProviderData: ProviderData{Line: "telecom", Weight: 50, Remark: "managed-by libdns"}}
+	recs, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{in})
+	if err != nil || len(recs) != 1 {
+		t.Fatal("excepted 1 record appended, got:", recs, err)
+	}
+	data, _ := providerDataOf(recs[0])
+	if data.RecordID == "" || data.Line != "telecom" || data.Weight != 50 || data.Remark != "managed-by libdns" {
+		t.Error("excepted the metadata of the appended record, got:", data)
+	}
+
+	recs, err = p.GetRecords(context.TODO(), "example.com.")
+	if err != nil || len(recs) != 1 {
+		t.Fatal("excepted 1 record, got:", recs, err)
+	}
+	data, _ = providerDataOf(recs[0])
+	ok := data.Line == "telecom" && data.Weight == 50 && data.Remark == "managed-by libdns" &&
+		data.Status == "ENABLE" && !data.Locked && !data.CreateTime.IsZero() && !data.UpdateTime.IsZero()
+	if !ok {
+		t.Error("excepted the metadata of the live record, got:", data)
+	}
+
+	// only the remark is updated if the data of the record is unchanged
+	in.ProviderData = ProviderData{Line: "telecom", Remark: "ticket-42"}
+	recs, err = p.SetRecords(context.TODO(), "example.com.", []libdns.Record{in})
+	if err != nil || len(recs) != 1 {
+		t.Fatal("excepted 1 record set, got:", recs, err)
+	}
+	data, _ = providerDataOf(recs[0])
+	if data.Remark != "ticket-42" || data.Line != "telecom" || data.Weight != 50 {
+		t.Error("excepted the remark updated, got:", data)
+	}
+	if n := srv.Requests("UpdateDomainRecord"); n != 0 {
+		t.Error("excepted no UpdateDomainRecord requests, got:", n)
+	}
+	if live := srv.Records("example.com"); len(live) != 1 || live[0].Remark != "ticket-42" {
+		t.Error("excepted the remark of the live record updated, got:", live)
+	}
+
+	// records on other lines are left alone
+	in.ProviderData = &ProviderData{Line: "unicom"}
+	if _, err = p.SetRecords(context.TODO(), "example.com.", []libdns.Record{in}); err != nil {
+		t.Fatal(err)
+	}
+	if live := srv.Records("example.com"); len(live) != 2 || live[0].Line != "telecom" || live[1].Line != "unicom" {
+		t.Error("excepted the records on both lines, got:", live)
+	}
+}
+
+func TestRecordRemark(t *testing.T) {
+	p, srv := fakeProvider(t)
+	rec, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		memo     string
+		remark   string
+		excepted bool
+	}{
+		{memo: "set remark", remark: "owner: sre, ticket-42", excepted: true},
+		{memo: "remark of 50 characters", remark: strings.Repeat("备", 50), excepted: true},
+		{memo: "remark too long", remark: strings.Repeat("x", 51), excepted: false},
+		{memo: "clear remark", remark: "", excepted: true},
+	}
+	for _, c := range cases {
+		before := srv.Requests("UpdateDomainRecordRemark")
+		err := p.SetRecordRemark(context.TODO(), rec.RecordID, c.remark)
+		if (err == nil) != c.excepted {
+			t.Errorf("excepted success %v, got: %v", c.excepted, err)
+			continue
+		}
+		if !c.excepted {
+			if !errors.Is(err, ErrInvalidRecord) || srv.Requests("UpdateDomainRecordRemark") != before {
+				t.Error("excepted ErrInvalidRecord before sending the request, got:", err)
+			}
+		} else if live := srv.Records("example.com"); live[0].Remark != c.remark {
+			t.Errorf("excepted remark %q, got: %q", c.remark, live[0].Remark)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+
+	// records with too long remarks are not added
+	_, err = p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "api", IP: netip.MustParseAddr("192.0.2.2"), ProviderData: ProviderData{Remark: strings.Repeat("x", 51)}},
+	})
+	if !errors.Is(err, ErrInvalidRecord) || len(srv.Records("example.com")) != 1 {
+		t.Error("excepted ErrInvalidRecord of the remark, got:", err)
+	}
+}
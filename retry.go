@@ -0,0 +1,118 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 200 * time.Millisecond
+	defaultRetryMaxDelay    = 5 * time.Second
+)
+
+// RetryPolicy configures how requests failed with throttling or transient errors are retried
+type RetryPolicy struct {
+	// Optional maximum attempts of a request including the first one, the default is 3,
+	// and 1 disables retrying
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// Optional delay before the first retry which is doubled for each later one,
+	// the default is 200ms
+	BaseDelay time.Duration `json:"base_delay,omitempty"`
+	// Optional maximum delay between attempts, the default is 5s
+	MaxDelay time.Duration `json:"max_delay,omitempty"`
+}
+
+// throttlingCodes are rejected before the request was processed,
+// so it is safe to retry any action with them.
+var throttlingCodes = []string{
+	"Throttling",
+	"ServiceUnavailable",
+}
+
+// transientCodes may be returned after the request was processed,
+// so only actions without side effects are retried with them.
+var transientCodes = []string{
+	"InternalError",
+	"UnknownError",
+	"ServiceTimeout",
+}
+
+func (r RetryPolicy) maxAttempts() int {
+	if r.MaxAttempts <= 0 {
+		return defaultRetryMaxAttempts
+	}
+	return r.MaxAttempts
+}
+
+// backoff returns the delay before the next attempt, with jitter in [delay/2, delay)
+func (r RetryPolicy) backoff(attempt int) time.Duration {
+	base, maxDelay := r.BaseDelay, r.MaxDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// outcomeUnknown reports whether the request failed with err may have been processed,
+// i.e. there was no response or the API responded with a server error.
+func outcomeUnknown(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.StatusCode >= 500
+}
+
+// retryable reports whether the request of the action failed with err is safe to retry
+func (r RetryPolicy) retryable(action string, err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// there was no response, the request may have been processed
+		return strings.HasPrefix(action, "Describe")
+	}
+	for _, prefix := range throttlingCodes {
+		if strings.HasPrefix(apiErr.Code, prefix) {
+			return true
+		}
+	}
+	if !strings.HasPrefix(action, "Describe") {
+		return false
+	}
+	for _, prefix := range transientCodes {
+		if strings.HasPrefix(apiErr.Code, prefix) {
+			return true
+		}
+	}
+	return apiErr.StatusCode >= 500
+}
+
+// sleepWithContext waits for the delay, it returns false without waiting
+// if the context would be done before the delay elapsed.
+func sleepWithContext(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
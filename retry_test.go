@@ -0,0 +1,140 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	type testCase struct {
+		memo   string
+		action string
+		err    error
+		result bool
+	}
+
+	cases := []testCase{
+		{memo: "throttled add", action: "AddDomainRecord", err: &APIError{StatusCode: 400, Code: "Throttling.User"}, result: true},
+		{memo: "unavailable update", action: "UpdateDomainRecord", err: &APIError{StatusCode: 503, Code: "ServiceUnavailable"}, result: true},
+		{memo: "internal error of add", action: "AddDomainRecord", err: &APIError{StatusCode: 500, Code: "InternalError"}, result: false},
+		{memo: "internal error of describe", action: "DescribeDomainRecords", err: &APIError{StatusCode: 500, Code: "InternalError"}, result: true},
+		{memo: "network error of add", action: "AddDomainRecord", err: errors.New("connection reset"), result: false},
+		{memo: "network error of describe", action: "DescribeDomains", err: errors.New("connection reset"), result: true},
+		{memo: "invalid parameter", action: "DescribeDomains", err: &APIError{StatusCode: 400, Code: "InvalidParameter"}, result: false},
+	}
+
+	for _, c := range cases {
+		if got := (RetryPolicy{}).retryable(c.action, c.err); got != c.result {
+			t.Log("case", c.memo, "excepted:", c.result, "got:", got)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	r := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	bounds := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, bound := range bounds {
+		delay := r.backoff(i + 1)
+		if delay < bound/2 || delay > bound {
+			t.Error("attempt", i+1, "excepted delay in", bound/2, bound, "got:", delay)
+		}
+	}
+}
+
+func TestSleepWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if sleepWithContext(ctx, time.Second) {
+		t.Error("excepted not to sleep beyond the deadline")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("excepted to return before the deadline")
+	}
+	if !sleepWithContext(context.Background(), time.Millisecond) {
+		t.Error("excepted to sleep")
+	}
+}
+
+func TestProviderRetry(t *testing.T) {
+	p, srv := fakeProvider(t)
+	srv.FailNext(2, http.StatusBadRequest, "Throttling.User")
+	p.RetryPolicy = RetryPolicy{BaseDelay: time.Millisecond}
+	recs, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+		libdns.RR{Name: "sub", Type: "A", Data: "1.1.1.1"},
+	})
+	if err != nil || len(recs) != 1 {
+		t.Fatal("excepted the record to be appended after retrying, got:", recs, err)
+	}
+	if n := srv.Requests("DescribeDomains"); n != 3 {
+		t.Error("excepted 3 attempts of DescribeDomains, got:", n)
+	}
+
+	srv.FailNext(3, http.StatusBadRequest, "Throttling.User")
+	p.RetryPolicy.MaxAttempts = 2
+	p.InvalidateZoneCache()
+	_, err = p.GetRecords(context.TODO(), "example.com.")
+	if !IsThrottled(err) {
+		t.Error("excepted the throttling error after max attempts, got:", err)
+	}
+}
+
+func TestAppendRetryDuplicate(t *testing.T) {
+	type testCase struct {
+		memo      string
+		existing  bool
+		status    int
+		code      string
+		duplicate bool
+	}
+
+	cases := []testCase{
+		{memo: "existing record with throttled attempt", existing: true, status: http.StatusBadRequest, code: "Throttling.User", duplicate: true},
+		{memo: "record added by unavailable attempt", existing: false, status: http.StatusServiceUnavailable, code: "ServiceUnavailable", duplicate: false},
+	}
+
+	for _, c := range cases {
+		p, srv := fakeProvider(t)
+		p.RetryPolicy = RetryPolicy{BaseDelay: time.Millisecond}
+		// the zone is cached, so the injected failures hit AddDomainRecord
+		if _, err := p.zoneInfo(context.TODO(), "example.com."); err != nil {
+			t.Fatal(err)
+		}
+		if c.existing {
+			if _, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "sub", Type: "A", Value: "1.1.1.1"}); err != nil {
+				t.Fatal(err)
+			}
+			srv.FailNext(1, c.status, c.code)
+		} else {
+			srv.FailAfter("AddDomainRecord", 1, c.status, c.code)
+		}
+		recs, err := p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+			libdns.RR{Name: "sub", Type: "A", Data: "1.1.1.1"},
+		})
+		if IsDuplicate(err) != c.duplicate {
+			t.Error("case", c.memo, "got unexcepted error:", err)
+			continue
+		}
+		live := srv.Records("example.com")
+		if len(live) != 1 || srv.Requests("AddDomainRecord") != 2 {
+			t.Error("case", c.memo, "excepted 2 attempts and 1 live record, got:", live)
+			continue
+		}
+		if !c.duplicate {
+			if data, _ := providerDataOf(recs[0]); len(recs) != 1 || data.RecordID != live[0].RecordID {
+				t.Error("case", c.memo, "excepted the ID of the live record, got:", recs)
+				continue
+			}
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
@@ -0,0 +1,112 @@
+package alidns
+
+import (
+	"github.com/libdns/libdns"
+)
+
+// rrSet groups the records sharing the same RR, type and line
+type rrSet struct {
+	Rr         string
+	DomainType string
+	Line       string
+	records    []aliDomainRecord
+	indexes    []int
+}
+
+// groupRRSets groups libdns.Record with zone into rrSets by the order of first appearance
+func groupRRSets(recs []libdns.Record, zone string) []*rrSet {
+	var result []*rrSet
+	sets := map[[3]string]*rrSet{}
+	for i, rec := range recs {
+		ar := alidnsRecord(rec, zone)
+		key := [3]string{ar.Rr, ar.DomainType, lineOf(ar.Line)}
+		set, ok := sets[key]
+		if !ok {
+			set = &rrSet{Rr: ar.Rr, DomainType: ar.DomainType, Line: lineOf(ar.Line)}
+			sets[key] = set
+			result = append(result, set)
+		}
+		set.records = append(set.records, ar)
+		set.indexes = append(set.indexes, i)
+	}
+	return result
+}
+
+// validate validates the records of the set, and returns the index of the invalid one in the input
+func (s *rrSet) validate() (int, error) {
+	for i := range s.records {
+		if err := validateRecord(&s.records[i]); err != nil {
+			return s.indexes[i], err
+		}
+	}
+	return 0, nil
+}
+
+// rrSetChanges describes how to turn the live RRset into the desired one.
+// Indexes of unchanged, updates and creates are refer to the desired records.
+type rrSetChanges struct {
+	unchanged map[int]aliDomainRecord
+	updates   map[int]aliDomainRecord
+	creates   []int
+	deletes   []aliDomainRecord
+}
+
+// diffRRSet matches desired records to existing ones, reusing the record IDs
+// of existing records wherever possible. TTLs of desired records are expected
+// to be normalized already.
+func diffRRSet(existing, desired []aliDomainRecord) rrSetChanges {
+	result := rrSetChanges{
+		unchanged: map[int]aliDomainRecord{},
+		updates:   map[int]aliDomainRecord{},
+	}
+	used := make([]bool, len(existing))
+	matched := make([]bool, len(desired))
+	pair := func(di, ei int) {
+		used[ei], matched[di] = true, true
+		rec := desired[di]
+		rec.RecordID = existing[ei].RecordID
+		sameLine := rec.Line == "" || rec.Line == existing[ei].Line
+		if rec.sameData(existing[ei]) && rec.TTL == existing[ei].TTL && sameLine {
+			result.unchanged[di] = existing[ei]
+		} else {
+			result.updates[di] = rec
+		}
+	}
+	match := func(fn func(d, e aliDomainRecord) bool) {
+		for di, d := range desired {
+			if matched[di] {
+				continue
+			}
+			for ei, e := range existing {
+				if !used[ei] && fn(d, e) {
+					pair(di, ei)
+					break
+				}
+			}
+		}
+	}
+	match(func(d, e aliDomainRecord) bool {
+		return d.RecordID != "" && d.RecordID == e.RecordID
+	})
+	match(func(d, e aliDomainRecord) bool {
+		return d.sameData(e)
+	})
+	// locked records are left for the last, since they cannot be updated
+	match(func(d, e aliDomainRecord) bool {
+		return !e.Locked
+	})
+	match(func(d, e aliDomainRecord) bool {
+		return true
+	})
+	for di := range desired {
+		if !matched[di] {
+			result.creates = append(result.creates, di)
+		}
+	}
+	for ei, e := range existing {
+		if !used[ei] {
+			result.deletes = append(result.deletes, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package alidns
+
+import (
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_groupRRSets(t *testing.T) {
+	recs := []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "1.1.1.1"},
+		libdns.RR{Name: "www", Type: "AAAA", Data: "::1"},
+		libdns.RR{Name: "www.mydomain.com.", Type: "A", Data: "1.1.1.2"},
+	}
+	sets := groupRRSets(recs, "mydomain.com.")
+	if len(sets) != 2 {
+		t.Log("excepted:", 2, "got:", len(sets))
+		t.FailNow()
+	}
+	if sets[0].DomainType != "A" || len(sets[0].records) != 2 || sets[0].indexes[1] != 2 {
+		t.Log("unexcepted A rrset:", sets[0])
+		t.Fail()
+	}
+	if sets[1].DomainType != "AAAA" || len(sets[1].records) != 1 || sets[1].indexes[0] != 1 {
+		t.Log("unexcepted AAAA rrset:", sets[1])
+		t.Fail()
+	}
+}
+
+func Test_diffRRSet(t *testing.T) {
+	type testCase struct {
+		memo      string
+		existing  []aliDomainRecord
+		desired   []aliDomainRecord
+		unchanged map[int]string
+		updates   map[int]string
+		creates   []int
+		deletes   []string
+	}
+
+	cases := []testCase{
+		{
+			memo: "unchanged records are left alone",
+			existing: []aliDomainRecord{
+				{RecordID: "1", DomainValue: "1.1.1.1", TTL: 600},
+			},
+			desired: []aliDomainRecord{
+				{DomainValue: "1.1.1.1", TTL: 600},
+			},
+			unchanged: map[int]string{0: "1"},
+		},
+		{
+			memo: "changed TTL updates in place",
+			existing: []aliDomainRecord{
+				{RecordID: "1", DomainValue: "1.1.1.1", TTL: 600},
+			},
+			desired: []aliDomainRecord{
+				{DomainValue: "1.1.1.1", TTL: 1200},
+			},
+			updates: map[int]string{0: "1"},
+		},
+		{
+			memo: "changed value reuses the record ID",
+			existing: []aliDomainRecord{
+				{RecordID: "1", DomainValue: "1.1.1.1", TTL: 600},
+				{RecordID: "2", DomainValue: "1.1.1.2", TTL: 600},
+			},
+			desired: []aliDomainRecord{
+				{DomainValue: "1.1.1.2", TTL: 600},
+				{DomainValue: "1.1.1.3", TTL: 600},
+			},
+			unchanged: map[int]string{0: "2"},
+			updates:   map[int]string{1: "1"},
+		},
+		{
+			memo: "missing records are created",
+			existing: []aliDomainRecord{
+				{RecordID: "1", DomainValue: "1.1.1.1", TTL: 600},
+			},
+			desired: []aliDomainRecord{
+				{DomainValue: "1.1.1.1", TTL: 600},
+				{DomainValue: "1.1.1.2", TTL: 600},
+			},
+			unchanged: map[int]string{0: "1"},
+			creates:   []int{1},
+		},
+		{
+			memo: "extra records are deleted",
+			existing: []aliDomainRecord{
+				{RecordID: "1", DomainValue: "1.1.1.1", TTL: 600},
+				{RecordID: "2", DomainValue: "1.1.1.2", TTL: 600},
+			},
+			desired: []aliDomainRecord{
+				{DomainValue: "1.1.1.2", TTL: 600},
+			},
+			unchanged: map[int]string{0: "2"},
+			deletes:   []string{"1"},
+		},
+		{
+			memo: "record ID in the input is preferred",
+			existing: []aliDomainRecord{
+				{RecordID: "1", DomainValue: "1.1.1.1", TTL: 600},
+				{RecordID: "2", DomainValue: "1.1.1.2", TTL: 600},
+			},
+			desired: []aliDomainRecord{
+				{RecordID: "2", DomainValue: "1.1.1.1", TTL: 600},
+			},
+			updates: map[int]string{0: "2"},
+			deletes: []string{"1"},
+		},
+		{
+			memo: "unlocked records are updated before locked ones",
+			existing: []aliDomainRecord{
+				{RecordID: "1", DomainValue: "1.1.1.1", TTL: 600, Locked: true},
+				{RecordID: "2", DomainValue: "1.1.1.2", TTL: 600},
+			},
+			desired: []aliDomainRecord{
+				{DomainValue: "1.1.1.3", TTL: 600},
+			},
+			updates: map[int]string{0: "2"},
+			deletes: []string{"1"},
+		},
+	}
+
+	for _, c := range cases {
+		changes := diffRRSet(c.existing, c.desired)
+		ok := len(changes.unchanged) == len(c.unchanged) &&
+			len(changes.updates) == len(c.updates) &&
+			len(changes.creates) == len(c.creates) &&
+			len(changes.deletes) == len(c.deletes)
+		for i, id := range c.unchanged {
+			ok = ok && changes.unchanged[i].RecordID == id
+		}
+		for i, id := range c.updates {
+			ok = ok && changes.updates[i].RecordID == id &&
+				changes.updates[i].DomainValue == c.desired[i].DomainValue
+		}
+		for i, di := range c.creates {
+			ok = ok && changes.creates[i] == di
+		}
+		for i, id := range c.deletes {
+			ok = ok && changes.deletes[i].RecordID == id
+		}
+		if !ok {
+			t.Log("case", c.memo, "got:", changes)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
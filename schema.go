@@ -17,6 +17,9 @@ import (
 
 const defaultRegionID string = "cn-hangzhou"
 const addressOfAPI string = "%s://alidns.aliyuncs.com/"
+const maxPageSizeOfDomains int = 100
+const maxPageSizeOfRecords int = 500
+const maxPageSizeOfSLBSubDomains int = 100
 
 // CredentialInfo implements param of the crediential
 type CredentialInfo struct {
@@ -47,14 +50,33 @@ type keyPair struct {
 	Value string
 }
 
+// apiEndpoint converts the host or URL of an endpoint to the address of API
+func apiEndpoint(endpoint string) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	mUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if mUrl.Host == "" {
+		return "", errors.New("alidns: invalid endpoint " + endpoint)
+	}
+	if mUrl.Path == "" {
+		mUrl.Path = "/"
+	}
+	return mUrl.String(), nil
+}
+
 func getClientSchema(cred *CredentialInfo, scheme string) (*aliClientSchema, error) {
-	if cred.AccessKeyID == "" || cred.AccessKeySecret == "" {
+	if cred == nil || cred.AccessKeyID == "" || cred.AccessKeySecret == "" {
 		return nil, errors.New("empty AccessKeyID or AccessKeySecret")
 	}
-	if len(cred.RegionID) == 0 {
-		cred.RegionID = defaultRegionID
+	tmp := *cred
+	if len(tmp.RegionID) == 0 {
+		tmp.RegionID = defaultRegionID
 	}
-	return defaultSchemaV3(cred, scheme)
+	return defaultSchemaV3(&tmp, scheme)
 }
 
 func (c *aliClientSchema) signReq(method string) error {
@@ -109,10 +109,29 @@ var p0 = Provider{
 }
 
 func Test_RequestUrl(t *testing.T) {
-	p0.getClient()
-	p0.client.SetRequestBody("Action", "DescribeDomainRecords")
-	p0.client.SetRequestBody("DomainName", "viscrop.top")
-	p0.client.SetRequestBody("Timestamp", "2020-10-16T20:10:54Z")
-	r, err := p0.client.schema.HttpRequest(context.TODO(), "GET")
+	cl, _ := p0.getClient(context.TODO())
+	cl.SetRequestBody("Action", "DescribeDomainRecords")
+	cl.SetRequestBody("DomainName", "viscrop.top")
+	cl.SetRequestBody("Timestamp", "2020-10-16T20:10:54Z")
+	r, err := cl.schema.HttpRequest(context.TODO(), "GET")
 	t.Log("url:", r.URL.String(), "err:", err)
 }
+
+func Test_apiEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"alidns.ap-southeast-1.aliyuncs.com": "https://alidns.ap-southeast-1.aliyuncs.com/",
+		"https://alidns.aliyuncs.com":        "https://alidns.aliyuncs.com/",
+		"http://127.0.0.1:8080/":             "http://127.0.0.1:8080/",
+		"http://127.0.0.1:8080/alidns/":      "http://127.0.0.1:8080/alidns/",
+	}
+	for endpoint, excepted := range cases {
+		got, err := apiEndpoint(endpoint)
+		if err != nil || got != excepted {
+			t.Log("excepted:", excepted, "got:", got, "err:", err)
+			t.Fail()
+		}
+	}
+	if _, err := apiEndpoint("http://"); err == nil {
+		t.Error("excepted error of invalid endpoint")
+	}
+}
@@ -0,0 +1,134 @@
+package alidns
+
+import (
+	"context"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// LoadBalancing is the weighted round-robin (DNS SLB) of the records sharing the same name and type
+type LoadBalancing struct {
+	// Name of the records relative to the zone
+	Name string
+	// Type of the records, e.g. A or AAAA
+	Type string
+	// Whether the answers are distributed by the weights of the records,
+	// otherwise all the records are answered
+	Enabled bool
+	// Number of the records
+	RecordCount int
+	// Records with their weights in ProviderData, only filled by GetLoadBalancing
+	Records []libdns.Record
+}
+
+type aliSLBSubDomain struct {
+	SubDomain   string `json:"SubDomain,omitempty"`
+	Type        string `json:"Type,omitempty"`
+	Open        bool   `json:"Open,omitempty"`
+	RecordCount int    `json:"RecordCount,omitempty"`
+}
+
+type aliSLBSubDomains struct {
+	SLBSubDomain []aliSLBSubDomain `json:"SlbSubDomain,omitempty"`
+}
+
+func (s aliSLBSubDomain) loadBalancing(zone string) LoadBalancing {
+	return LoadBalancing{
+		Name:        libdns.RelativeName(s.SubDomain, strings.Trim(zone, ".")),
+		Type:        s.Type,
+		Enabled:     s.Open,
+		RecordCount: s.RecordCount,
+	}
+}
+
+// SetLoadBalancing enables or disables the weighted round-robin of the records
+// with the name and type, e.g. A or AAAA, in the zone.
+func (p *Provider) SetLoadBalancing(ctx context.Context, zone, name, recType string, enabled bool) error {
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return OpError("SetLoadBalancing", err)
+	}
+	subDomain := libdns.AbsoluteName(name, strings.Trim(zone, "."))
+	err = cl.setDNSSLBStatus(ctx, subDomain, zone, recType, enabled)
+	if err != nil {
+		return OpError("SetLoadBalancing", err)
+	}
+	return nil
+}
+
+// SetRecordWeight sets the weight of the record by its ID, which is between 1 and 100.
+// The weights only take effect once the load balancing of the records is enabled.
+func (p *Provider) SetRecordWeight(ctx context.Context, recordID string, weight int) error {
+	err := validateWeight(weight)
+	if err != nil {
+		return OpError("SetRecordWeight", err)
+	}
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return OpError("SetRecordWeight", err)
+	}
+	err = cl.setDNSSLBWeight(ctx, recordID, weight)
+	if err != nil {
+		return OpError("SetRecordWeight", err)
+	}
+	return nil
+}
+
+// ListLoadBalancing lists the names and types in the zone which the load balancing is available for,
+// i.e. the ones with multiple records or with the load balancing enabled.
+func (p *Provider) ListLoadBalancing(ctx context.Context, zone string) ([]LoadBalancing, error) {
+	subDomains, err := p.querySLBSubDomains(ctx, zone, "")
+	if err != nil {
+		return nil, OpError("ListLoadBalancing", err)
+	}
+	var result []LoadBalancing
+	for _, s := range subDomains {
+		result = append(result, s.loadBalancing(zone))
+	}
+	return result, nil
+}
+
+// GetLoadBalancing gets the load balancing of the records with the name and type in the zone,
+// together with the records and their weights.
+func (p *Provider) GetLoadBalancing(ctx context.Context, zone, name, recType string) (LoadBalancing, error) {
+	rr := libdns.RelativeName(libdns.AbsoluteName(name, strings.Trim(zone, ".")), strings.Trim(zone, "."))
+	result := LoadBalancing{Name: rr, Type: recType}
+	subDomains, err := p.querySLBSubDomains(ctx, zone, rr)
+	if err != nil {
+		return result, OpError("GetLoadBalancing", err)
+	}
+	for _, s := range subDomains {
+		lb := s.loadBalancing(zone)
+		if strings.EqualFold(lb.Name, rr) && lb.Type == recType {
+			result.Enabled = lb.Enabled
+		}
+	}
+	recs, err := p.querySubDomainRecords(ctx, zone, rr, recType)
+	if err != nil {
+		return result, OpError("GetLoadBalancing", err)
+	}
+	for _, rec := range recs {
+		result.Records = append(result.Records, rec.libdnsRecord())
+	}
+	result.RecordCount = len(result.Records)
+	return result, nil
+}
+
+func (p *Provider) querySLBSubDomains(ctx context.Context, zone, rr string) ([]aliSLBSubDomain, error) {
+	var result []aliSLBSubDomain
+	for pageNumber := 1; ; pageNumber++ {
+		cl, err := p.getClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := cl.queryDNSSLBSubDomains(ctx, zone, rr, pageNumber, maxPageSizeOfSLBSubDomains)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rs.SLBSubDomains.SLBSubDomain...)
+		if len(rs.SLBSubDomains.SLBSubDomain) == 0 || len(result) >= rs.TotalCount {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package alidns
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func TestLoadBalancing(t *testing.T) {
+	p, srv := fakeProvider(t)
+	var ids []string
+	for _, value := range []string{"192.0.2.1", "192.0.2.2"} {
+		rec, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "www", Type: "A", Value: value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, rec.RecordID)
+	}
+	if _, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "api", Type: "A", Value: "192.0.2.3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lbs, err := p.ListLoadBalancing(context.TODO(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lbs) != 1 || lbs[0].Name != "www" || lbs[0].Type != "A" || lbs[0].Enabled || lbs[0].RecordCount != 2 {
+		t.Error("excepted the load balancing of www available, got:", lbs)
+	}
+
+	if err = p.SetLoadBalancing(context.TODO(), "example.com.", "www", "A", true); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.SetRecordWeight(context.TODO(), ids[0], 80); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.SetRecordWeight(context.TODO(), ids[1], 20); err != nil {
+		t.Fatal(err)
+	}
+	lb, err := p.GetLoadBalancing(context.TODO(), "example.com.", "www", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lb.Enabled || lb.RecordCount != 2 {
+		t.Fatal("excepted the load balancing of www enabled, got:", lb)
+	}
+	weights := map[string]int{}
+	for _, rec := range lb.Records {
+		data, _ := providerDataOf(rec)
+		weights[data.RecordID] = data.Weight
+	}
+	if weights[ids[0]] != 80 || weights[ids[1]] != 20 {
+		t.Error("excepted the weights 80 and 20, got:", weights)
+	}
+
+	cases := []struct {
+		memo     string
+		name     string
+		enabled  bool
+		excepted bool
+	}{
+		{memo: "disable", name: "www", enabled: false, excepted: true},
+		{memo: "single record", name: "api", enabled: true, excepted: true},
+		{memo: "no records", name: "nonexistent", enabled: true, excepted: false},
+	}
+	for _, c := range cases {
+		err := p.SetLoadBalancing(context.TODO(), "example.com.", c.name, "A", c.enabled)
+		if (err == nil) != c.excepted {
+			t.Errorf("excepted success %v, got: %v", c.excepted, err)
+			continue
+		}
+		if err == nil {
+			lb, err := p.GetLoadBalancing(context.TODO(), "example.com.", c.name, "A")
+			if err != nil || lb.Enabled != c.enabled {
+				t.Errorf("excepted enabled %v, got: %v, %v", c.enabled, lb, err)
+				continue
+			}
+		} else if !IsNotFound(err) {
+			t.Error("excepted not found error, got:", err)
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestInvalidWeight(t *testing.T) {
+	p, srv := fakeProvider(t)
+	rec, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "www", Type: "A", Value: "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		memo     string
+		weight   int
+		excepted bool
+	}{
+		{memo: "minimum weight", weight: 1, excepted: true},
+		{memo: "maximum weight", weight: 100, excepted: true},
+		{memo: "zero weight", weight: 0, excepted: false},
+		{memo: "negative weight", weight: -1, excepted: false},
+		{memo: "weight too large", weight: 101, excepted: false},
+	}
+	for _, c := range cases {
+		before := srv.Requests("UpdateDNSSLBWeight")
+		err := p.SetRecordWeight(context.TODO(), rec.RecordID, c.weight)
+		if (err == nil) != c.excepted {
+			t.Errorf("excepted success %v, got: %v", c.excepted, err)
+			continue
+		}
+		if !c.excepted && (!errors.Is(err, ErrInvalidRecord) || srv.Requests("UpdateDNSSLBWeight") != before) {
+			t.Error("excepted ErrInvalidRecord before sending the request, got:", err)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+
+	// records with out-of-range weights are not added
+	_, err = p.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "api", IP: netip.MustParseAddr("192.0.2.2"), ProviderData: ProviderData{Weight: 101}},
+	})
+	if !errors.Is(err, ErrInvalidRecord) || len(srv.Records("example.com")) != 1 {
+		t.Error("excepted ErrInvalidRecord of the weight, got:", err)
+	}
+}
@@ -0,0 +1,68 @@
+package alidns
+
+import (
+	"context"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// statuses of records accepted by SetDomainRecordStatus and DescribeDomainRecords,
+// the Status of live records is responded in upper case, e.g. ENABLE
+const (
+	statusEnable  = "Enable"
+	statusDisable = "Disable"
+)
+
+// disabled reports whether the live record is disabled
+func (r aliDomainRecord) disabled() bool {
+	return strings.EqualFold(r.Status, statusDisable)
+}
+
+// EnableRecords enables the records in the zone, so they are answered again.
+// Records without IDs are looked up, and locked records are handled as DeleteRecords does.
+// It returns the records that were enabled.
+func (p *Provider) EnableRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	return p.setRecordsStatus(ctx, "EnableRecords", zone, recs, statusEnable)
+}
+
+// DisableRecords disables the records in the zone, so they are kept but not answered until they are enabled.
+// Records without IDs are looked up, and locked records are handled as DeleteRecords does.
+// It returns the records that were disabled.
+func (p *Provider) DisableRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	return p.setRecordsStatus(ctx, "DisableRecords", zone, recs, statusDisable)
+}
+
+func (p *Provider) setRecordsStatus(ctx context.Context, op, zone string, recs []libdns.Record, status string) ([]libdns.Record, error) {
+	var rls []libdns.Record
+	var errs = OpErrors(op)
+	cl, err := p.getClientWithZone(ctx, zone)
+	if err != nil {
+		return nil, OpError(op, err)
+	}
+	targets, ok := p.resolveTargets(ctx, cl, zone, recs, errs)
+	if !ok {
+		return nil, errs.Error()
+	}
+	for _, target := range targets {
+		rec := target.record
+		if !strings.EqualFold(rec.Status, status) {
+			err = p.setDomainRecordStatus(ctx, rec.RecordID, status)
+			if err != nil {
+				target.joinError(errs, err)
+				continue
+			}
+			rec.Status = strings.ToUpper(status)
+		}
+		rls = append(rls, rec.libdnsRecord())
+	}
+	return rls, errs.Error()
+}
+
+func (p *Provider) setDomainRecordStatus(ctx context.Context, recID, status string) error {
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	return cl.setDomainRecordStatus(ctx, recID, status)
+}
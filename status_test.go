@@ -0,0 +1,112 @@
+package alidns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func TestRecordStatus(t *testing.T) {
+	p, srv := fakeProvider(t)
+	for _, value := range []string{"192.0.2.1", "192.0.2.2"} {
+		if _, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "www", Type: "A", Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, err := p.DisableRecords(context.TODO(), "example.com.", []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+	})
+	if err != nil || len(recs) != 1 {
+		t.Fatal("excepted 1 record disabled, got:", recs, err)
+	}
+	if data, _ := providerDataOf(recs[0]); data.Status != "DISABLE" {
+		t.Error("excepted the status of the disabled record, got:", data.Status)
+	}
+	disabledID := recs[0].(libdns.Address).ProviderData.(ProviderData).RecordID
+
+	cases := []struct {
+		memo     string
+		exclude  bool
+		excepted int
+	}{
+		{memo: "include disabled records", exclude: false, excepted: 2},
+		{memo: "exclude disabled records", exclude: true, excepted: 1},
+	}
+	for _, c := range cases {
+		p.ExcludeDisabledRecords = c.exclude
+		recs, err := p.GetRecords(context.TODO(), "example.com.")
+		if err != nil || len(recs) != c.excepted {
+			t.Errorf("excepted %d records, got: %v, %v", c.excepted, recs, err)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+
+	// records are enabled by their IDs, and enabled records are left alone
+	recs, err = p.EnableRecords(context.TODO(), "example.com.", []libdns.Record{
+		DomainRecord{ID: disabledID, Name: "www", Type: "A"},
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.2"},
+	})
+	if err != nil || len(recs) != 2 {
+		t.Fatal("excepted 2 records enabled, got:", recs, err)
+	}
+	if n := srv.Requests("SetDomainRecordStatus"); n != 2 {
+		t.Error("excepted 2 SetDomainRecordStatus requests, got:", n)
+	}
+	for _, rec := range srv.Records("example.com") {
+		if rec.Status != "ENABLE" {
+			t.Error("excepted the record enabled, got:", rec)
+		}
+	}
+}
+
+func TestSetRecordsDisabled(t *testing.T) {
+	cases := []struct {
+		memo     string
+		enable   bool
+		value    string
+		locked   bool
+		excepted string
+	}{
+		{memo: "unchanged record kept disabled", value: "192.0.2.1", excepted: "DISABLE"},
+		{memo: "updated record kept disabled", value: "192.0.2.9", excepted: "DISABLE"},
+		{memo: "locked record kept disabled", value: "192.0.2.1", locked: true, excepted: "DISABLE"},
+		{memo: "unchanged record", enable: true, value: "192.0.2.1", excepted: "ENABLE"},
+		{memo: "updated record", enable: true, value: "192.0.2.9", excepted: "ENABLE"},
+		{memo: "locked record", enable: true, value: "192.0.2.1", locked: true, excepted: "DISABLE"},
+	}
+	for _, c := range cases {
+		p, srv := fakeProvider(t)
+		p.EnableDisabledRecords = c.enable
+		if _, err := srv.AddRecord(alidnstest.Record{DomainName: "example.com", RR: "c", Type: "A", Value: "192.0.2.1",
+			Status: "DISABLE", Locked: c.locked}); err != nil {
+			t.Fatal(err)
+		}
+		recs, err := p.SetRecords(context.TODO(), "example.com.", []libdns.Record{
+			libdns.RR{Name: "c", Type: "A", Data: c.value, TTL: 600 * time.Second},
+		})
+		failed := c.enable && c.locked
+		if (err != nil) != failed || (failed && !IsLocked(err)) {
+			t.Error("case", c.memo, "got unexcepted error:", err)
+			continue
+		}
+		if live := srv.Records("example.com"); len(live) != 1 || live[0].Status != c.excepted || live[0].Value != c.value {
+			t.Errorf("excepted the record %s, got: %v", c.excepted, live)
+			continue
+		}
+		if !failed {
+			if data, _ := providerDataOf(recs[0]); len(recs) != 1 || data.Status != c.excepted {
+				t.Error("excepted the record returned in status", c.excepted, "got:", recs)
+				continue
+			}
+		}
+		if n := srv.Requests("SetDomainRecordStatus"); !c.enable && n != 0 {
+			t.Error("excepted no SetDomainRecordStatus requests, got:", n)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
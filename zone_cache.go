@@ -0,0 +1,124 @@
+package alidns
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultZoneCacheTTL = 10 * time.Minute
+
+// aliZoneInfo is the metadata of a zone which requests on its records rely on
+type aliZoneInfo struct {
+	DomainName string
+	Edition    instanceEdition
+	MinTTL     ttl_t
+	// Lines supported by the zone, which are looked up lazily
+	Lines    []Line
+	expireAt time.Time
+}
+
+// zoneCache caches aliZoneInfo by zone, it is safe for concurrent use
+type zoneCache struct {
+	mutex sync.RWMutex
+	zones map[string]aliZoneInfo
+}
+
+func zoneKey(zone string) string {
+	return strings.ToLower(strings.Trim(zone, "."))
+}
+
+func (c *zoneCache) get(zone string) (aliZoneInfo, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	info, ok := c.zones[zoneKey(zone)]
+	if !ok || time.Now().After(info.expireAt) {
+		return aliZoneInfo{}, false
+	}
+	return info, true
+}
+
+func (c *zoneCache) set(zone string, info aliZoneInfo, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.zones == nil {
+		c.zones = map[string]aliZoneInfo{}
+	}
+	info.expireAt = time.Now().Add(ttl)
+	c.zones[zoneKey(zone)] = info
+}
+
+// setLines sets the lines of the cached zone, the expiration of the zone is kept
+func (c *zoneCache) setLines(zone string, lines []Line) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	info, ok := c.zones[zoneKey(zone)]
+	if !ok {
+		return
+	}
+	info.Lines = lines
+	c.zones[zoneKey(zone)] = info
+}
+
+func (c *zoneCache) invalidate(zones ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(zones) == 0 {
+		c.zones = nil
+		return
+	}
+	for _, zone := range zones {
+		delete(c.zones, zoneKey(zone))
+	}
+}
+
+// InvalidateZoneCache drops the cached metadata of the zones,
+// or of all zones if none is specified.
+func (p *Provider) InvalidateZoneCache(zones ...string) {
+	p.zones.invalidate(zones...)
+}
+
+func (p *Provider) zoneCacheTTL() time.Duration {
+	if p.ZoneCacheTTL == 0 {
+		return defaultZoneCacheTTL
+	}
+	return p.ZoneCacheTTL
+}
+
+// zoneInfo looks up the metadata of the zone, from the cache if possible.
+// MinTTL is only known if DescribeDomainInfo is allowed for the credential,
+// otherwise it is derived from the instance edition. Other errors of DescribeDomainInfo are returned.
+func (p *Provider) zoneInfo(ctx context.Context, zone string) (aliZoneInfo, error) {
+	if info, ok := p.zones.get(zone); ok {
+		return info, nil
+	}
+	cl, err := p.getClient(ctx)
+	if err != nil {
+		return aliZoneInfo{}, err
+	}
+	domain, err := cl.queryDomainInfo(ctx, zoneKey(zone))
+	if err != nil {
+		return aliZoneInfo{}, err
+	}
+	info := aliZoneInfo{
+		DomainName: domain.DomainName,
+		Edition:    domain.VersionCode,
+	}
+	cl, err = p.getClient(ctx)
+	if err != nil {
+		return aliZoneInfo{}, err
+	}
+	rs, err := cl.queryDomainDetail(ctx, domain.DomainName)
+	if err != nil && !IsAuth(err) {
+		return aliZoneInfo{}, err
+	}
+	if err == nil && rs.MinTTL > 0 {
+		info.MinTTL = ttl_t(rs.MinTTL)
+	}
+	p.zones.set(zone, info, p.zoneCacheTTL())
+	return info, nil
+}
@@ -0,0 +1,141 @@
+package alidns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/libdns/alidns/alidnstest"
+	"github.com/libdns/libdns"
+)
+
+func Test_zoneCache(t *testing.T) {
+	var c zoneCache
+	if _, ok := c.get("example.com"); ok {
+		t.Error("excepted empty cache")
+	}
+	c.set("Example.com.", aliZoneInfo{DomainName: "example.com"}, time.Minute)
+	if info, ok := c.get("example.com"); !ok || info.DomainName != "example.com" {
+		t.Error("excepted cached zone, got:", info)
+	}
+	c.invalidate("example.com.")
+	if _, ok := c.get("example.com"); ok {
+		t.Error("excepted invalidated zone")
+	}
+	c.set("example.com", aliZoneInfo{DomainName: "example.com"}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := c.get("example.com"); ok {
+		t.Error("excepted expired zone")
+	}
+	c.set("example.com", aliZoneInfo{DomainName: "example.com"}, -1)
+	if _, ok := c.get("example.com"); ok {
+		t.Error("excepted disabled cache")
+	}
+}
+
+func Test_aliClientTTLOf(t *testing.T) {
+	type testCase struct {
+		memo   string
+		info   aliZoneInfo
+		ttl    ttl_t
+		result ttl_t
+	}
+
+	cases := []testCase{
+		{memo: "default TTL", info: aliZoneInfo{Edition: EditionEnterpriseBasic}, ttl: 0, result: 600},
+		{memo: "free edition", info: aliZoneInfo{Edition: EditionFree}, ttl: 60, result: 600},
+		{memo: "enterprise edition", info: aliZoneInfo{Edition: EditionEnterpriseBasic}, ttl: 60, result: 60},
+		{memo: "MinTTL of zone", info: aliZoneInfo{Edition: EditionEnterpriseBasic, MinTTL: 120}, ttl: 60, result: 120},
+	}
+
+	for _, c := range cases {
+		cl := (&aliClient{}).withZone(c.info)
+		if got := cl.ttlOf(c.ttl); got != c.result {
+			t.Log("case", c.memo, "excepted:", c.result, "got:", got)
+			t.Fail()
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}
+
+func TestProviderZoneCache(t *testing.T) {
+	p, srv := fakeProvider(t)
+	for i := 0; i < 3; i++ {
+		recs := []libdns.Record{libdns.RR{Name: "sub", Type: "A", Data: fmt.Sprintf("1.1.1.%d", i)}}
+		if _, err := p.AppendRecords(context.TODO(), "example.com.", recs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := srv.Requests("DescribeDomains"); n != 1 {
+		t.Error("excepted 1 DescribeDomains request, got:", n)
+	}
+	p.InvalidateZoneCache("example.com")
+	recs := []libdns.Record{libdns.RR{Name: "sub", Type: "A", Data: "1.1.1.3"}}
+	if _, err := p.AppendRecords(context.TODO(), "example.com.", recs); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Requests("DescribeDomains"); n != 2 {
+		t.Error("excepted 2 DescribeDomains requests, got:", n)
+	}
+}
+
+func TestProviderCanonicalZone(t *testing.T) {
+	p, srv := fakeProvider(t)
+	srv.AddZone(alidnstest.Zone{DomainName: "例子.中国", PunyCode: "xn--fsqu00a.xn--fiqs8s"})
+	zone := "xn--fsqu00a.xn--fiqs8s."
+	recs, err := p.AppendRecords(context.TODO(), zone, []libdns.Record{
+		libdns.RR{Name: "old", Type: "TXT", Data: "decommissioned"},
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+	})
+	if err != nil || len(recs) != 2 {
+		t.Fatal("excepted 2 records appended to the canonical zone, got:", recs, err)
+	}
+	recs, err = p.DeleteRecords(context.TODO(), zone, []libdns.Record{libdns.RR{Name: "www", Type: "A"}})
+	if err != nil || len(recs) != 1 {
+		t.Error("excepted 1 record deleted from the canonical zone, got:", recs, err)
+	}
+	recs, err = p.DeleteSubDomainRecords(context.TODO(), zone, "old", "")
+	if err != nil || len(recs) != 1 {
+		t.Error("excepted 1 record deleted from the canonical zone, got:", recs, err)
+	}
+	if live := srv.Records("例子.中国"); len(live) != 0 {
+		t.Error("excepted no records left, got:", live)
+	}
+}
+
+func TestProviderZoneInfoErrors(t *testing.T) {
+	type testCase struct {
+		memo   string
+		status int
+		code   string
+		fails  bool
+	}
+
+	cases := []testCase{
+		{memo: "DescribeDomainInfo forbidden", status: http.StatusForbidden, code: "Forbidden.RAM", fails: false},
+		{memo: "DescribeDomainInfo throttled", status: http.StatusBadRequest, code: "Throttling.User", fails: true},
+	}
+
+	for _, c := range cases {
+		p, srv := fakeProvider(t)
+		p.RetryPolicy = RetryPolicy{MaxAttempts: 1}
+		srv.FailAfter("DescribeDomainInfo", 1, c.status, c.code)
+		info, err := p.zoneInfo(context.TODO(), "example.com.")
+		if (err != nil) != c.fails {
+			t.Error("case", c.memo, "got unexcepted error:", err)
+			continue
+		}
+		if _, cached := p.zones.get("example.com"); cached == c.fails {
+			t.Error("case", c.memo, "excepted cached:", !c.fails)
+			continue
+		}
+		if !c.fails && info.MinTTL != 0 {
+			t.Error("case", c.memo, "excepted MinTTL derived from the edition, got:", info.MinTTL)
+			continue
+		}
+		t.Log("case ", c.memo, "was pass.")
+	}
+}